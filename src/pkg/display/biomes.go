@@ -0,0 +1,278 @@
+package display
+
+import (
+	"math/rand"
+
+	"main/pkg/gmgmap"
+
+	"github.com/fatih/color"
+)
+
+const (
+	mapWidth       = 150
+	mapHeight      = 50
+	levelsPerBiome = 3 // Two levels then the boss of the band
+)
+
+type biome struct {
+	Name     string
+	generate func(rng *rand.Rand) *gmgmap.Map
+	Symbols  map[rune]string // Drawn instead of the default tile symbols
+}
+
+// The town and the shops have their own generators, see town.go
+var townBiome = biome{Name: "Town"}
+
+// Depth bands, the list starts over past the last one
+var biomes = []biome{
+	{
+		Name: "Crypt",
+		generate: func(rng *rand.Rand) *gmgmap.Map {
+			return openingsToDoors(gmgmap.NewBSPInterior(rng, func(_ *gmgmap.Map) {}, mapWidth, mapHeight, 3, 15, 3))
+		},
+		Symbols: map[rune]string{
+			gmgmap.Room:  color.New(color.FgHiBlack, color.BgHiBlack).Sprint("█"),
+			gmgmap.Room2: color.New(color.FgWhite, color.BgHiBlack).Sprint("░"),
+			gmgmap.Wall2: color.New(color.FgWhite).Sprint("▓"),
+		},
+	},
+	{
+		Name: "Tunnels",
+		generate: func(rng *rand.Rand) *gmgmap.Map {
+			return gmgmap.NewRogue(rng, mapWidth, mapHeight, 5, 3, 60, 90)
+		},
+		Symbols: map[rune]string{
+			gmgmap.Room:  color.New(color.FgYellow, color.BgHiBlack).Sprint("░"),
+			gmgmap.Room2: color.New(color.FgYellow).Sprint("▒"),
+			gmgmap.Wall2: color.New(color.FgYellow, color.Bold).Sprint("█"),
+		},
+	},
+	{
+		Name: "Caves",
+		generate: func(rng *rand.Rand) *gmgmap.Map {
+			return stonesToWalls(gmgmap.NewCellularAutomata(rng, mapWidth, mapHeight, 45, 4, 5, 1))
+		},
+		Symbols: map[rune]string{
+			gmgmap.Floor: color.New(color.FgYellow).Sprint("·"),
+			gmgmap.Wall2: color.New(color.FgRed).Sprint("▓"),
+		},
+	},
+	{
+		Name: "Ruined halls",
+		generate: func(rng *rand.Rand) *gmgmap.Map {
+			return gmgmap.NewInterior(rng, mapWidth, mapHeight, 8, 20, gmgmap.LobbyAny)
+		},
+		Symbols: map[rune]string{
+			gmgmap.Room:  color.New(color.FgCyan, color.BgHiBlack).Sprint("▒"),
+			gmgmap.Room2: color.New(color.FgHiBlack, color.BgHiBlack).Sprint("░"),
+			gmgmap.Wall2: color.New(color.FgCyan).Sprint("▓"),
+		},
+	},
+	{
+		Name: "Catacombs",
+		generate: func(rng *rand.Rand) *gmgmap.Map {
+			return gmgmap.NewBSP(rng, mapWidth, mapHeight, 4, 8, 3)
+		},
+		Symbols: map[rune]string{
+			gmgmap.Room:  color.New(color.FgMagenta, color.BgHiBlack).Sprint("░"),
+			gmgmap.Room2: color.New(color.FgMagenta).Sprint("·"),
+			gmgmap.Wall2: color.New(color.FgMagenta, color.Bold).Sprint("█"),
+		},
+	},
+	{
+		Name: "Overgrown depths",
+		generate: func(rng *rand.Rand) *gmgmap.Map {
+			return walkToFloor(gmgmap.NewRandomWalk(rng, mapWidth, mapHeight, 4000))
+		},
+		Symbols: map[rune]string{
+			gmgmap.Floor: color.New(color.FgGreen).Sprint("\""),
+			gmgmap.Wall2: color.New(color.FgGreen, color.Bold).Sprint("♠"),
+		},
+	},
+}
+
+func biomeFor(level int) biome {
+	if level == 0 {
+		return townBiome
+	}
+	if level > 0 {
+		return biome{Name: shops[level-1].Name}
+	}
+	return biomes[((-level-1)/levelsPerBiome)%len(biomes)]
+}
+
+func tileSymbol(b biome, tile rune) string {
+	if symbol, ok := b.Symbols[tile]; ok {
+		return symbol
+	}
+	return gmgmap.GetTileSymbol(tile)
+}
+
+// Floor tile with nothing blocking, ignoring entities
+func isWalkable(m *gmgmap.Map, x, y int) bool {
+	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return false
+	}
+	switch m.Layer("Ground").GetTile(x, y) {
+	case gmgmap.Room, gmgmap.Room2, gmgmap.Floor, gmgmap.Grass, gmgmap.Road, gmgmap.Road2:
+	default:
+		return false
+	}
+	switch m.Layer("Structures").GetTile(x, y) {
+	case gmgmap.Wall, gmgmap.Wall2, gmgmap.Tree, gmgmap.Sign:
+		return false
+	}
+	switch layerTile(m, "Furniture", x, y) {
+	case gmgmap.Counter, gmgmap.Shelf, gmgmap.Table, gmgmap.Pot:
+		return false
+	}
+	return true
+}
+
+// Tile of an optional layer, without adding the layer to the map
+func layerTile(m *gmgmap.Map, name string, x, y int) rune {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l.GetTile(x, y)
+		}
+	}
+	return gmgmap.Nothing
+}
+
+// Cellular automata stones become cave walls
+func stonesToWalls(m *gmgmap.Map) *gmgmap.Map {
+	structuresLayer := m.Layer("Structures")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if structuresLayer.GetTile(x, y) == gmgmap.Road2 {
+				structuresLayer.SetTile(x, y, gmgmap.Wall2)
+			} else {
+				structuresLayer.SetTile(x, y, gmgmap.Nothing)
+			}
+		}
+	}
+	return m
+}
+
+// The random walk leaves trees on its path, the path becomes the floor and the rest is overgrown
+func walkToFloor(m *gmgmap.Map) *gmgmap.Map {
+	structuresLayer := m.Layer("Structures")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if structuresLayer.GetTile(x, y) == gmgmap.Tree {
+				structuresLayer.SetTile(x, y, gmgmap.Nothing)
+			} else {
+				structuresLayer.SetTile(x, y, gmgmap.Wall2)
+			}
+		}
+	}
+	return m
+}
+
+// Entities are 2 chars wide, passages only 1 tile wide get a second column
+func widenPassages(m *gmgmap.Map) {
+	for y := 0; y < m.Height-1; y++ {
+		for x := 0; x < m.Width-1; x++ {
+			if !isWalkable(m, x, y) || !isWalkable(m, x, y+1) {
+				continue
+			}
+			leftOpen := isWalkable(m, x-1, y) && isWalkable(m, x-1, y+1)
+			rightOpen := isWalkable(m, x+1, y) && isWalkable(m, x+1, y+1)
+			if !leftOpen && !rightOpen {
+				carve(m, x+1, y, x, y)
+				carve(m, x+1, y+1, x, y+1)
+			}
+		}
+	}
+}
+
+// Opens x,y with the same ground as the tile next to it
+func carve(m *gmgmap.Map, x, y, fromX, fromY int) {
+	if isWalkable(m, x, y) {
+		return
+	}
+	ground := m.Layer("Ground")
+	structuresLayer := m.Layer("Structures")
+	ground.SetTile(x, y, ground.GetTile(fromX, fromY))
+	if tile := structuresLayer.GetTile(x, y); gmgmap.IsWall(tile) || tile == gmgmap.Tree {
+		structuresLayer.SetTile(x, y, gmgmap.Nothing)
+	}
+}
+
+// Generators don't all place stairs, so every level gets its own: up and down as far apart as possible
+func placeStairs(m *gmgmap.Map, rng *rand.Rand, withUp bool) {
+	structuresLayer := m.Layer("Structures")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if gmgmap.IsStairs(structuresLayer.GetTile(x, y)) {
+				structuresLayer.SetTile(x, y, gmgmap.Nothing)
+			}
+		}
+	}
+
+	var spots [][]int
+	for _, spot := range freeSpots(m) {
+		if structuresLayer.GetTile(spot[0], spot[1]) == gmgmap.Nothing && structuresLayer.GetTile(spot[0]+1, spot[1]) == gmgmap.Nothing {
+			spots = append(spots, spot)
+		}
+	}
+	if len(spots) == 0 {
+		return
+	}
+
+	// Retried a few times so the stairs don't end up in a small closed off pocket
+	var up []int
+	var reached map[[2]int]bool
+	for try := 0; try < 10 && len(reached) < len(spots)/2; try++ {
+		candidate := spots[rng.Intn(len(spots))]
+		if candidateReached := reachable(m, candidate); len(candidateReached) > len(reached) {
+			up, reached = candidate, candidateReached
+		}
+	}
+	down := up
+	for _, spot := range spots {
+		if reached[[2]int{spot[0], spot[1]}] && distance(spot, up) > distance(down, up) {
+			down = spot
+		}
+	}
+	if withUp {
+		structuresLayer.SetTile(up[0], up[1], gmgmap.StairsUp)
+	}
+	structuresLayer.SetTile(down[0], down[1], gmgmap.StairsDown)
+}
+
+func distance(a, b []int) int {
+	dx, dy := a[0]-b[0], a[1]-b[1]
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+// Spots a 2 chars wide entity can walk to from start, ignoring entities
+func reachable(m *gmgmap.Map, start []int) map[[2]int]bool {
+	return reachableWith(start, func(x, y int) bool {
+		return isWalkable(m, x, y) && isWalkable(m, x+1, y)
+	})
+}
+
+// Spots passable says the entity can stand on, reached from start
+func reachableWith(start []int, passable func(x, y int) bool) map[[2]int]bool {
+	seen := map[[2]int]bool{{start[0], start[1]}: true}
+	queue := [][2]int{{start[0], start[1]}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			n := [2]int{p[0] + d[0], p[1] + d[1]}
+			if !seen[n] && passable(n[0], n[1]) {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return seen
+}
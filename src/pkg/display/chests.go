@@ -0,0 +1,168 @@
+package display
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"main/pkg/fight"
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+)
+
+// Chest state per dungeon level, saved so opened chests stay looted
+var chests = map[int][]structures.Chest{}
+
+func loadChests() {
+	loaded := map[int][]structures.Chest{}
+	if err := save.LoadAny("chests", &loaded); err == nil {
+		chests = loaded
+	}
+}
+
+func saveChests() {
+	_ = save.SaveAny("chests", chests)
+}
+
+func chestSpotFree(m *gmgmap.Map, x, y int) bool {
+	ground := m.Layer("Ground")
+	entities := m.Layer("Entities")
+	for i := 0; i < 2; i++ {
+		tile := ground.GetTile(x+i, y)
+		if tile != gmgmap.Room && tile != gmgmap.Room2 && tile != gmgmap.Floor {
+			return false
+		}
+		if entities.GetTile(x+i, y) != gmgmap.Nothing {
+			return false
+		}
+	}
+	return true
+}
+
+// Puts back the saved chests of the level, or rolls new ones in the dead-end rooms, and returns the spots of the vault
+func placeChests(m *gmgmap.Map, level int, rng *rand.Rand) map[[2]int]bool {
+	entities := m.Layer("Entities")
+	var vault map[[2]int]bool
+	if _, ok := chests[level]; !ok {
+		spots := gmgmap.FindDeadEndRooms(m, m.Height-1)
+		for i := range spots {
+			j := rng.Intn(i + 1)
+			spots[i], spots[j] = spots[j], spots[i]
+		}
+		count := 2 + rng.Intn(3)
+		if count > len(spots) {
+			count = len(spots)
+		}
+		levelChests := []structures.Chest{}
+		if level < 0 { // Town doors are never locked
+			if spot, area, ok := makeVault(m, rng); ok {
+				levelChests = append(levelChests, structures.VaultChest(spot[0], spot[1]))
+				vault = area
+			}
+		}
+		for _, spot := range spots[:count] {
+			levelChests = append(levelChests, structures.RollChest(spot[0], spot[1], -level))
+		}
+		chests[level] = levelChests
+		saveChests()
+	} else {
+		vault = vaultArea(m)
+	}
+
+	for _, c := range chests[level] {
+		if c.Opened || !chestSpotFree(m, c.X, c.Y) {
+			continue
+		}
+		entities.SetTile(c.X, c.Y, gmgmap.Chest)
+		entities.SetTile(c.X+1, c.Y, gmgmap.Chest) // Chests are 2 chars wide
+		fmt.Printf("Chest at: (%d, %d) - (%d, %d)\n", c.X, c.Y, c.X+1, c.Y)
+	}
+	return vault
+}
+
+// Finds the chest the player walked into, the player and the chest are both 2 chars wide
+func findChest(level, x, y int) *structures.Chest {
+	for i := range chests[level] {
+		c := &chests[level][i]
+		if !c.Opened && c.Y == y && c.X >= x-1 && c.X <= x+1 {
+			return c
+		}
+	}
+	return nil
+}
+
+func askYesNo(question string) bool {
+	fmt.Print(question + " (y/n): ")
+	var ans string
+	fmt.Scanln(&ans)
+	ans = strings.TrimSpace(strings.ToLower(ans))
+	return len(ans) > 0 && ans[0] == 'y'
+}
+
+// Tries to get the chest open, returns false if it stays locked
+func unlockChest(player *structures.Player, c *structures.Chest) bool {
+	fmt.Println("The chest is locked.")
+	if player.HasKey() && askYesNo(fmt.Sprintf("Use a %s?", structures.ChestKey.Name)) {
+		player.Unlock(c, true)
+		fmt.Println("The key turns with a click.")
+		return true
+	}
+	if c.Jammed {
+		fmt.Println("The lock is jammed, only a key will open it now.")
+		return false
+	}
+	if !askYesNo(fmt.Sprintf("Try to pick the lock? (%d%% chance)", player.LockpickChance())) {
+		return false
+	}
+	if player.Unlock(c, false) {
+		fmt.Println("You pick the lock!")
+		return true
+	}
+	fmt.Println("The lock jams! You'll need a key to open this one.")
+	return false
+}
+
+// Opens the chest the player walked into, mimics start a fight instead
+func openChest(player *structures.Player, c *structures.Chest, depth int) {
+	if c.Mimic {
+		fmt.Println("The chest springs open, revealing rows of teeth. It's a Mimic!")
+		mimic := structures.InitMimic(depth)
+		_ = fight.StartFight(player, &mimic)
+		if player.Entity.Alive {
+			c.Opened = true
+		}
+		return
+	}
+
+	if c.IsLocked() && !unlockChest(player, c) {
+		fmt.Println("Press Enter to continue...")
+		fmt.Scanln()
+		return
+	}
+
+	items, gold := c.Loot(depth)
+	player.Money += gold
+	received, leftBehind := player.AddItems(items)
+	c.Opened = true
+
+	fmt.Printf("You open the chest and find %d coins and:\n", gold)
+	for _, entry := range received {
+		fmt.Printf("  + %s\n", entry.GetItem().Name)
+	}
+	if len(leftBehind) > 0 {
+		fmt.Println("Too heavy to carry, dropped on the floor:")
+		for _, entry := range leftBehind {
+			fmt.Printf("  - %s\n", entry.GetItem().Name)
+		}
+		player.DropOnFloor(leftBehind)
+	}
+	fmt.Println("Press Enter to continue...")
+	fmt.Scanln()
+}
+
+func removeChestTile(m *gmgmap.Map, c *structures.Chest) {
+	entities := m.Layer("Entities")
+	entities.SetTile(c.X, c.Y, gmgmap.Nothing)
+	entities.SetTile(c.X+1, c.Y, gmgmap.Nothing)
+}
@@ -0,0 +1,136 @@
+package display
+
+import (
+	"fmt"
+
+	"main/pkg/gmgmap"
+)
+
+// Generated again when corridors can't link everything up
+const maxLevelAttempts = 3
+
+// Where the player comes in: their own spot, else the up stairs, else the down stairs
+func levelStart(m *gmgmap.Map) ([]int, bool) {
+	entities := m.Layer("Entities")
+	structuresLayer := m.Layer("Structures")
+	var up, down []int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if entities.GetTile(x, y) == gmgmap.Player {
+				return []int{x, y}, true
+			}
+			switch structuresLayer.GetTile(x, y) {
+			case gmgmap.StairsUp:
+				up = standingSpot(m, x, y)
+			case gmgmap.StairsDown:
+				down = standingSpot(m, x, y)
+			}
+		}
+	}
+	if up != nil {
+		return up, true
+	}
+	return down, down != nil
+}
+
+// The player stands on the stairs with one of their 2 tiles
+func standingSpot(m *gmgmap.Map, x, y int) []int {
+	if !isWalkable(m, x+1, y) && isWalkable(m, x-1, y) {
+		return []int{x - 1, y}
+	}
+	return []int{x, y}
+}
+
+// Stairs, merchant, blacksmith and alchemist the player must be able to walk to
+func levelTargets(m *gmgmap.Map) [][]int {
+	entities := m.Layer("Entities")
+	structuresLayer := m.Layer("Structures")
+	var targets [][]int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if gmgmap.IsStairs(structuresLayer.GetTile(x, y)) {
+				targets = append(targets, standingSpot(m, x, y))
+			}
+			if tile := entities.GetTile(x, y); (tile == gmgmap.Merchant || tile == gmgmap.Blacksmith || tile == gmgmap.Alchemist) && entities.GetTile(x-1, y) != tile {
+				targets = append(targets, []int{x, y})
+			}
+		}
+	}
+	return targets
+}
+
+// Spots a 2 chars wide entity stands on, entities and doors don't block the way but locked doors need a key
+func spotPassable(m *gmgmap.Map) func(x, y int) bool {
+	return func(x, y int) bool {
+		return isWalkable(m, x, y) && isWalkable(m, x+1, y) && !isLockedDoor(m, x, y) && !isLockedDoor(m, x+1, y)
+	}
+}
+
+func isLockedDoor(m *gmgmap.Map, x, y int) bool {
+	return m.Layer("Structures").GetTile(x, y) == gmgmap.DoorLocked
+}
+
+func isConnected(m *gmgmap.Map, start, target []int) bool {
+	_, found := gmgmap.FindPath(m.Width, m.Height, spotPassable(m), start[0], start[1], target[0], target[1])
+	return found
+}
+
+// Carves 2 tiles wide corridors to the targets the player can't reach, returns false if some are still cut off
+func connectLevel(m *gmgmap.Map) bool {
+	start, ok := levelStart(m)
+	if !ok {
+		return false
+	}
+	// Nothing is carved into the vault or its walls, the key opens the way
+	vault := vaultArea(m)
+	sealed := map[[2]int]bool{}
+	for spot := range vault {
+		for y := spot[1] - 1; y <= spot[1]+1; y++ {
+			for x := spot[0] - 1; x <= spot[0]+2; x++ {
+				sealed[[2]int{x, y}] = true
+			}
+		}
+	}
+	connected := true
+	for _, target := range levelTargets(m) {
+		if vault[[2]int{target[0], target[1]}] || isConnected(m, start, target) {
+			continue
+		}
+		// The corridor starts from the closest spot the player already reaches, the order of the scan keeps it deterministic
+		reached := reachableWith(start, spotPassable(m))
+		var from []int
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				spot := []int{x, y}
+				if reached[[2]int{x, y}] && (from == nil || distance(spot, target) < distance(from, target)) {
+					from = spot
+				}
+			}
+		}
+		if from == nil {
+			connected = false
+			continue
+		}
+		inside := func(x, y int) bool {
+			if x == target[0] && y == target[1] { // Already open, carving leaves it as it is
+				return true
+			}
+			return x >= 1 && x+1 < m.Width-1 && y >= 1 && y < m.Height-1 && !isLockedDoor(m, x, y) && !isLockedDoor(m, x+1, y) &&
+				!sealed[[2]int{x, y}] && !sealed[[2]int{x + 1, y}]
+		}
+		path, found := gmgmap.FindPath(m.Width, m.Height, inside, from[0], from[1], target[0], target[1])
+		if !found {
+			connected = false
+			continue
+		}
+		for i := 1; i < len(path); i++ {
+			carve(m, path[i][0], path[i][1], path[i-1][0], path[i-1][1])
+			carve(m, path[i][0]+1, path[i][1], path[i][0], path[i][1])
+		}
+		fmt.Printf("Corridor carved from (%d, %d) to (%d, %d)\n", from[0], from[1], target[0], target[1])
+		if !isConnected(m, start, target) {
+			connected = false
+		}
+	}
+	return connected
+}
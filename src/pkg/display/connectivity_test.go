@@ -0,0 +1,127 @@
+package display
+
+import (
+	"testing"
+
+	"main/pkg/gmgmap"
+)
+
+// Map from rows of chars: # wall, . floor, r room, c corridor, < > stairs, x locked door, M B A merchant, blacksmith, alchemist
+func buildMap(rows []string) *gmgmap.Map {
+	m := gmgmap.NewMap(len(rows[0]), len(rows))
+	ground := m.Layer("Ground")
+	structuresLayer := m.Layer("Structures")
+	entities := m.Layer("Entities")
+	for y, row := range rows {
+		for x, c := range row {
+			ground.SetTile(x, y, gmgmap.Floor)
+			switch c {
+			case '#':
+				ground.SetTile(x, y, gmgmap.Nothing)
+				structuresLayer.SetTile(x, y, gmgmap.Wall)
+			case 'r':
+				ground.SetTile(x, y, gmgmap.Room)
+			case 'c':
+				ground.SetTile(x, y, gmgmap.Room2)
+			case '<':
+				structuresLayer.SetTile(x, y, gmgmap.StairsUp)
+			case '>':
+				structuresLayer.SetTile(x, y, gmgmap.StairsDown)
+			case 'x':
+				structuresLayer.SetTile(x, y, gmgmap.DoorLocked)
+			case 'M':
+				entities.SetTile(x, y, gmgmap.Merchant)
+			case 'B':
+				entities.SetTile(x, y, gmgmap.Blacksmith)
+			case 'A':
+				entities.SetTile(x, y, gmgmap.Alchemist)
+			}
+		}
+	}
+	return m
+}
+
+func TestLevelTargetsFindsStairsAndShopkeepers(t *testing.T) {
+	m := buildMap([]string{
+		"##########",
+		"#<......>#",
+		"#MM.BB.AA#",
+		"##########",
+	})
+	want := [][]int{{1, 1}, {7, 1}, {1, 2}, {4, 2}, {7, 2}}
+	got := levelTargets(m)
+	if len(got) != len(want) {
+		t.Fatalf("targets = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i][0] != want[i][0] || got[i][1] != want[i][1] {
+			t.Fatalf("targets = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestConnectLevelCarvesToCutOffStairs(t *testing.T) {
+	m := buildMap([]string{
+		"####################",
+		"#<.....#.......>...#",
+		"#......#...........#",
+		"#......#...........#",
+		"####################",
+	})
+	start, _ := levelStart(m)
+	if isConnected(m, start, []int{15, 1}) {
+		t.Fatal("down stairs reachable before the repair")
+	}
+	if !connectLevel(m) {
+		t.Fatal("connectLevel could not link the stairs")
+	}
+	if !isConnected(m, start, []int{15, 1}) {
+		t.Fatal("down stairs still cut off after the repair")
+	}
+}
+
+func TestConnectLevelLeavesVaultClosed(t *testing.T) {
+	rows := []string{
+		"####################",
+		"#<..........#......#",
+		"#...........x..AA..#",
+		"#...........x......#",
+		"####################",
+	}
+	m := buildMap(rows)
+	if !vaultArea(m)[[2]int{15, 2}] {
+		t.Fatal("alchemist not seen behind the vault door")
+	}
+	if !connectLevel(m) {
+		t.Fatal("connectLevel failed on a level with a vault")
+	}
+	before := buildMap(rows).Layer("Structures")
+	after := m.Layer("Structures")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if before.GetTile(x, y) != after.GetTile(x, y) {
+				t.Fatalf("tile (%d, %d) changed from %c to %c, the vault was opened", x, y, before.GetTile(x, y), after.GetTile(x, y))
+			}
+		}
+	}
+}
+
+func TestOpeningsToDoorsOnlyClosesRoomOpenings(t *testing.T) {
+	m := openingsToDoors(buildMap([]string{
+		"#########cccc",
+		"#rrrrrrr#cccc",
+		"#rrrrrrrccccc",
+		"#rrrrrrrccccc",
+		"#rrrrrrr#cccc",
+		"#########cccc",
+	}))
+	structuresLayer := m.Layer("Structures")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			opening := x == 8 && (y == 2 || y == 3)
+			if isDoor := structuresLayer.GetTile(x, y) == gmgmap.Door; isDoor != opening {
+				t.Fatalf("door at (%d, %d) = %v, want %v", x, y, isDoor, opening)
+			}
+		}
+	}
+}
@@ -16,9 +16,8 @@ import (
 	"github.com/awesome-gocui/gocui"
 )
 
-func spawnEntities(m *gmgmap.Map, rng *rand.Rand) {
+func spawnEntities(m *gmgmap.Map, level int, rng *rand.Rand) {
 	entities := m.Layer("Entities")
-	ground := m.Layer("Ground")
 
 	playerExists := false
 	for y := 0; y < m.Height; y++ {
@@ -33,20 +32,25 @@ func spawnEntities(m *gmgmap.Map, rng *rand.Rand) {
 		}
 	}
 
-	var validSpawns [][]int
-	for y := 1; y < m.Height-1 && y <= 32; y++ { // Don't spawn below y=32
-		for x := 1; x < m.Width-2; x++ { // Leave room for 2-character wide entities
-			tile1 := ground.GetTile(x, y)
-			tile2 := ground.GetTile(x+1, y)
-			entityTile1 := entities.GetTile(x, y)
-			entityTile2 := entities.GetTile(x+1, y)
+	if level > 0 { // Shops are furnished by generateShop
+		return
+	}
 
-			// Both tiles must be valid floor/room tiles and empty
-			if (tile1 == gmgmap.Room || tile1 == gmgmap.Room2 || tile1 == gmgmap.Floor) &&
-				(tile2 == gmgmap.Room || tile2 == gmgmap.Room2 || tile2 == gmgmap.Floor) &&
-				entityTile1 == gmgmap.Nothing && entityTile2 == gmgmap.Nothing {
-				validSpawns = append(validSpawns, []int{x, y})
-			}
+	if level == 0 && !playerExists { // The player starts next to the dungeon entrance
+		if spot, ok := townStart(m); ok {
+			entities.SetTile(spot[0], spot[1], gmgmap.Player)
+			entities.SetTile(spot[0]+1, spot[1], gmgmap.Player) // Player is 2 chars wide
+			playerExists = true
+		}
+	}
+
+	vault := placeChests(m, level, rng)
+	placeFloorLoot(m, level)
+
+	var validSpawns [][]int
+	for _, spot := range freeSpots(m) {
+		if !vault[[2]int{spot[0], spot[1]}] { // Nobody waits behind the locked vault door
+			validSpawns = append(validSpawns, spot)
 		}
 	}
 
@@ -70,7 +74,7 @@ func spawnEntities(m *gmgmap.Map, rng *rand.Rand) {
 		fmt.Printf("Player at: (%d, %d) - (%d, %d)\n", spawn[0], spawn[1], spawn[0]+1, spawn[1])
 	}
 
-	if spawnIndex < len(validSpawns) {
+	if level != 0 && spawnIndex < len(validSpawns) { // In town they wait in their shops
 		spawn := validSpawns[spawnIndex]
 		entities.SetTile(spawn[0], spawn[1], gmgmap.Merchant)
 		entities.SetTile(spawn[0]+1, spawn[1], gmgmap.Merchant) // Merchant is 2 chars wide
@@ -78,7 +82,7 @@ func spawnEntities(m *gmgmap.Map, rng *rand.Rand) {
 		fmt.Printf("Merchant at: (%d, %d) - (%d, %d)\n", spawn[0], spawn[1], spawn[0]+1, spawn[1])
 	}
 
-	if spawnIndex < len(validSpawns) {
+	if level != 0 && spawnIndex < len(validSpawns) {
 		spawn := validSpawns[spawnIndex]
 		entities.SetTile(spawn[0], spawn[1], gmgmap.Blacksmith)
 		entities.SetTile(spawn[0]+1, spawn[1], gmgmap.Blacksmith) // Blacksmith is 2 chars wide
@@ -86,7 +90,37 @@ func spawnEntities(m *gmgmap.Map, rng *rand.Rand) {
 		fmt.Printf("Blacksmith at: (%d, %d) - (%d, %d)\n", spawn[0], spawn[1], spawn[0]+1, spawn[1])
 	}
 
-	numMobs := rng.Intn(8) + 8
+	if spawnIndex < len(validSpawns) {
+		spawn := validSpawns[spawnIndex]
+		entities.SetTile(spawn[0], spawn[1], gmgmap.Alchemist)
+		entities.SetTile(spawn[0]+1, spawn[1], gmgmap.Alchemist) // Alchemist is 2 chars wide
+		spawnIndex++
+		fmt.Printf("Alchemist at: (%d, %d) - (%d, %d)\n", spawn[0], spawn[1], spawn[0]+1, spawn[1])
+	}
+
+	if level < 0 && !connectLevel(m) { // Corridors to the merchant, blacksmith and alchemist if they landed in a closed off pocket
+		fmt.Println("Warning: Some of the level can't be reached!")
+	}
+
+	if level == 0 && spawnIndex < len(validSpawns) { // The stash only stands in town
+		spawn := validSpawns[spawnIndex]
+		entities.SetTile(spawn[0], spawn[1], gmgmap.Stash)
+		entities.SetTile(spawn[0]+1, spawn[1], gmgmap.Stash) // Stash is 2 chars wide
+		spawnIndex++
+		fmt.Printf("Stash at: (%d, %d) - (%d, %d)\n", spawn[0], spawn[1], spawn[0]+1, spawn[1])
+	}
+
+	if level == 0 && portal.Open && spawnIndex < len(validSpawns) { // Town portal left open in a previous session
+		spawn := validSpawns[spawnIndex]
+		entities.SetTile(spawn[0], spawn[1], gmgmap.Portal)
+		entities.SetTile(spawn[0]+1, spawn[1], gmgmap.Portal) // Portal is 2 chars wide
+		spawnIndex++
+	}
+
+	numMobs := 0
+	if level != 0 { // The village is safe, only villagers walk its streets
+		numMobs = rng.Intn(8) + 8
+	}
 	for i := 0; i < numMobs && spawnIndex < len(validSpawns); i++ {
 		spawn := validSpawns[spawnIndex]
 		entities.SetTile(spawn[0], spawn[1], gmgmap.Mob)
@@ -98,6 +132,25 @@ func spawnEntities(m *gmgmap.Map, rng *rand.Rand) {
 	fmt.Printf("Total entities spawned: %d\n", spawnIndex)
 }
 
+// Floor spots where a 2 chars wide entity fits, scanned top to bottom
+func freeSpots(m *gmgmap.Map) [][]int {
+	entities := m.Layer("Entities")
+	var spots [][]int
+	for y := 1; y < m.Height-1; y++ {
+		for x := 1; x < m.Width-2; x++ { // Leave room for 2-character wide entities
+			entityTile1 := entities.GetTile(x, y)
+			entityTile2 := entities.GetTile(x+1, y)
+
+			// Both tiles must be valid floor/room tiles and empty
+			if isWalkable(m, x, y) && isWalkable(m, x+1, y) &&
+				entityTile1 == gmgmap.Nothing && entityTile2 == gmgmap.Nothing {
+				spots = append(spots, []int{x, y})
+			}
+		}
+	}
+	return spots
+}
+
 func findPlayer(m *gmgmap.Map) (int, int) {
 	entities := m.Layer("Entities")
 	for y := 0; y < m.Height; y++ {
@@ -116,31 +169,23 @@ func canMoveTo(m *gmgmap.Map, x, y int) bool {
 		return false
 	}
 
-	if y > 32 { // else the player can go outside the view cuz of bottom
-		return false
-	}
-
-	ground := m.Layer("Ground")
-	structures := m.Layer("Structures")
 	entities := m.Layer("Entities")
 
 	for i := 0; i < 2; i++ {
-		groundTile := ground.GetTile(x+i, y)
-		structureTile := structures.GetTile(x+i, y)
 		entityTile := entities.GetTile(x+i, y)
 
-		validGround := (groundTile == gmgmap.Room || groundTile == gmgmap.Room2 ||
-			groundTile == gmgmap.Floor)
-
-		invalidStructure := (structureTile == gmgmap.Wall || structureTile == gmgmap.Wall2)
-
 		blockedByEntity := entityTile != gmgmap.Nothing &&
 			entityTile != gmgmap.Mob &&
 			entityTile != gmgmap.Merchant &&
 			entityTile != gmgmap.Blacksmith &&
+			entityTile != gmgmap.Alchemist &&
+			entityTile != gmgmap.Chest &&
+			entityTile != gmgmap.Stash &&
+			entityTile != gmgmap.Portal &&
+			entityTile != gmgmap.LootPile &&
 			entityTile != gmgmap.Player
 
-		if !validGround || invalidStructure || blockedByEntity {
+		if !isWalkable(m, x+i, y) || isClosedDoor(m, x+i, y) || blockedByEntity {
 			return false
 		}
 	}
@@ -165,6 +210,8 @@ type GameState struct {
 	maps             map[int]*gmgmap.Map
 	gui              *gocui.Gui
 	player           *structures.Player
+	lastMove         time.Time // Encumbered players can't step as often
+	viewW, viewH     int       // Size of the game view the camera was last drawn for
 }
 
 var gameState *GameState
@@ -172,7 +219,8 @@ var gameState *GameState
 func gameLayout(g *gocui.Gui) error {
 	maxX, maxY := g.Size()
 
-	if v, err := g.SetView("game", 0, 0, maxX-1, maxY-4, 0); err != nil {
+	v, err := g.SetView("game", 0, 0, maxX-1, maxY-4, 0)
+	if err != nil {
 		if !errors.Is(err, gocui.ErrUnknownView) {
 			return err
 		}
@@ -183,6 +231,8 @@ func gameLayout(g *gocui.Gui) error {
 		if _, err := g.SetCurrentView("game"); err != nil {
 			return err
 		}
+	} else if w, h := v.Size(); gameState != nil && (w != gameState.viewW || h != gameState.viewH) { // Resized terminal
+		updateGameView(v)
 	}
 
 	if v, err := g.SetView("status", 0, maxY-4, maxX-1, maxY-1, 0); err != nil {
@@ -194,7 +244,7 @@ func gameLayout(g *gocui.Gui) error {
 		updateStatusView(v)
 	}
 
-	return nil
+	return mapLayout(g)
 }
 
 func updateGameView(v *gocui.View) {
@@ -207,46 +257,99 @@ func updateGameView(v *gocui.View) {
 	m := gameState.gameMap
 	ground := m.Layer("Ground")
 	entities := m.Layer("Entities")
+	b := biomeFor(gameState.currentLevel)
+	width, height := v.Size()
+	gameState.viewW, gameState.viewH = width, height
+	camX, camY := cameraOrigin(m, width, height)
 
-	for y := 0; y < m.Height; y++ {
+	lit := isLit(gameState.currentLevel)
+	var visible, mask [][]bool
+	if !lit {
+		visible = visibleTiles(m, gameState.currentLevel, gameState.playerX, gameState.playerY)
+		mask = exploredMask(gameState.currentLevel, m)
+	}
+
+	for y := camY; y < m.Height && y < camY+height; y++ {
 		line := ""
 		skipNext := false
 
-		for x := 0; x < m.Width; x++ {
+		// Scanned from the left edge of the map so 2 chars wide entities stay paired
+		for x := 0; x < m.Width && x < camX+width; x++ {
 			if skipNext {
 				skipNext = false
+				if x == camX { // Right half of an entity cut by the left edge of the view
+					line += " "
+				}
 				continue
 			}
 
+			inSight := lit || visible[y][x]
+			remembered := !inSight && mask[y][x]
+
 			entityTile := entities.GetTile(x, y)
 			if entityTile != gmgmap.Nothing && gmgmap.IsDoubleWidthEntity(entityTile) {
-				groundTile := ground.GetTile(x, y)
-				line += gmgmap.GetEntitySymbolWithBackground(entityTile, groundTile)
-				skipNext = true
-				continue
-			}
-
-			rendered := false
-			for i := len(m.Layers) - 1; i >= 0; i-- {
-				l := m.Layers[i]
-				tile := l.GetTile(x, y)
-				if i == 0 || tile != gmgmap.Nothing {
-					if l.Name == "Entities" && tile != gmgmap.Nothing {
+				pairInSight := inSight || (x+1 < m.Width && visible[y][x+1])
+				if entityTile == gmgmap.Mob && !pairInSight { // Mobs out of sight are hidden, the floor under them is drawn
+					if x >= camX {
+						line += drawTile(m, b, x, y, inSight, remembered)
+					}
+					if x+1 >= camX && x+1 < m.Width && x+1 < camX+width {
+						line += drawTile(m, b, x+1, y, false, mask[y][x+1])
+					}
+					skipNext = true
+					continue
+				}
+				if pairInSight || remembered {
+					if x >= camX {
 						groundTile := ground.GetTile(x, y)
-						line += gmgmap.GetEntitySymbolWithBackground(tile, groundTile)
-					} else {
-						line += gmgmap.GetTileSymbol(tile)
+						line += gmgmap.GetEntitySymbolWithBackground(entityTile, groundTile)
 					}
-					rendered = true
-					break
+					skipNext = true
+					continue
 				}
 			}
-			if !rendered {
-				line += " "
+			if x < camX {
+				continue
 			}
+			line += drawTile(m, b, x, y, inSight, remembered)
 		}
 		fmt.Fprintln(v, line)
 	}
+
+	if gameState.gui != nil {
+		refreshMaps(gameState.gui)
+	}
+}
+
+// Top tile of the layers at x,y, faded when out of sight and blank when never seen
+func drawTile(m *gmgmap.Map, b biome, x, y int, inSight, remembered bool) string {
+	if !inSight && !remembered {
+		return " "
+	}
+	ground := m.Layer("Ground")
+	for i := len(m.Layers) - 1; i >= 0; i-- {
+		l := m.Layers[i]
+		tile := l.GetTile(x, y)
+		if l.Name == "Entities" && tile == gmgmap.Mob && !inSight {
+			continue
+		}
+		if l.Name == "Structures" && gmgmap.IsTrap(tile) && !trapDetected(gameState.currentLevel, x, y) { // Hidden traps look like the floor
+			continue
+		}
+		if i == 0 || tile != gmgmap.Nothing {
+			symbol := ""
+			if l.Name == "Entities" && tile != gmgmap.Nothing {
+				symbol = gmgmap.GetEntitySymbolWithBackground(tile, ground.GetTile(x, y))
+			} else {
+				symbol = tileSymbol(b, tile)
+			}
+			if remembered {
+				symbol = dimSymbol(symbol)
+			}
+			return symbol
+		}
+	}
+	return " "
 }
 
 func updateStatusView(v *gocui.View) {
@@ -284,10 +387,13 @@ func updateStatusView(v *gocui.View) {
 	}
 	xpBar += "]"
 
-	fmt.Fprintf(v, "HP: %d/%d | Gold: %d | Mana: %d | Level: %d | XP: %s %d/100 | Dungeon: %d (%s)",
+	fmt.Fprintf(v, "HP: %d/%d | Gold: %d | Mana: %d | Level: %d | XP: %s %d/100 | Dungeon: %d - %s (%s)",
 		gameState.player.Entity.HP, gameState.player.Entity.MaxHP, gameState.player.Money,
-		gameState.player.Mana, gameState.player.Entity.Level, xpBar, xpProgress, gameState.currentLevel, difficultyDesc)
-	fmt.Fprint(v, "\nZ=Up S=Down Q=Left D=Right F=Stairs E=Inventory X=Exit ESC=Menu | 😊=You 😈=Enemies 👑=Merchant ⚒️=Blacksmith")
+		gameState.player.Mana, gameState.player.Entity.Level, xpBar, xpProgress, gameState.currentLevel, biomeFor(gameState.currentLevel).Name, difficultyDesc)
+	if load := gameState.player.Encumbrance(); load.InitiativePenalty > 0 {
+		fmt.Fprintf(v, " | %s", load.Name)
+	}
+	fmt.Fprint(v, "\nZ=Up S=Down Q=Left D=Right F=Stairs E=Inventory O=Door T=Search M=Minimap C=Map N=Marker X=Exit ESC=Menu | 😊=You 😈=Enemies 👑=Merchant ⚒️=Blacksmith 🧪=Alchemist 📦=Chest 🏦=Stash 🌀=Portal 💰=Loot 🧑=Villager")
 }
 
 func moveUp(g *gocui.Gui, v *gocui.View) error {
@@ -307,6 +413,7 @@ func moveRight(g *gocui.Gui, v *gocui.View) error {
 }
 
 func exitGame(g *gocui.Gui, v *gocui.View) error {
+	saveCurrentLevel()
 	return gocui.ErrQuit
 }
 
@@ -318,7 +425,7 @@ func openInventory(g *gocui.Gui, v *gocui.View) error {
 }
 
 func generateBossLevel() *gmgmap.Map {
-	width, height := 150, 40
+	width, height := mapWidth, mapHeight
 	m := gmgmap.NewMap(width, height)
 
 	ground := m.Layer("Ground")
@@ -348,25 +455,24 @@ func generateBossLevel() *gmgmap.Map {
 }
 
 func generateMapForLevel(level int, rng *rand.Rand) *gmgmap.Map {
-	width, height := 150, 40
-	splits := 3
-	minRoomSize := 15
-	corridorWidth := 3
-
-	m := gmgmap.NewBSPInterior(rng, func(_ *gmgmap.Map) {}, width, height, splits, minRoomSize, corridorWidth)
-
-	structures := m.Layer("Structures")
-
 	if level == 0 {
-		for y := 0; y < m.Height; y++ {
-			for x := 0; x < m.Width; x++ {
-				if structures.GetTile(x, y) == gmgmap.StairsUp {
-					structures.SetTile(x, y, gmgmap.StairsDown)
-				}
-			}
+		return generateTown(rng)
+	}
+	if level > 0 {
+		return generateShop(level, rng)
+	}
+	// The same seed gives the same attempts, so a level is always generated the same way
+	var m *gmgmap.Map
+	for attempt := 0; attempt < maxLevelAttempts; attempt++ {
+		m = biomeFor(level).generate(rng)
+		widenPassages(m)
+		placeStairs(m, rng, level != 0)
+		if connectLevel(m) {
+			break
 		}
+		fmt.Printf("Level %d cut off, generating it again\n", level)
 	}
-
+	placeTraps(m, level, rng)
 	return m
 }
 
@@ -403,32 +509,30 @@ func useStairs(g *gocui.Gui, v *gocui.View) error {
 		return nil
 	}
 
+	var targetStairs rune = gmgmap.StairsUp
+	if currentTile == gmgmap.StairsUp {
+		targetStairs = gmgmap.StairsDown
+	}
+	return enterLevel(g, newLevel, targetStairs)
+}
+
+// Moves the player to the level, next to the given stairs when there are some
+func enterLevel(g *gocui.Gui, newLevel int, targetStairs rune) error {
 	oldMap := gameState.gameMap
 	if oldMap != nil {
 		oldMap.Layer("Entities").SetTile(gameState.playerX, gameState.playerY, gmgmap.Nothing)
+		oldMap.Layer("Entities").SetTile(gameState.playerX+1, gameState.playerY, gmgmap.Nothing)
+		saveLevel(gameState.currentLevel, oldMap)
 	}
 
-	if gameState.maps[newLevel] == nil {
-		if newLevel < 0 && (newLevel%3) == 0 {
-			gameState.maps[newLevel] = generateBossLevel()
-		} else {
-			rng := structures.GetRNG()
-			gameState.maps[newLevel] = generateMapForLevel(newLevel, rng)
-		}
-	}
+	gameState.maps[newLevel] = levelMap(newLevel)
 
 	gameState.currentLevel = newLevel
 	gameState.gameMap = gameState.maps[newLevel]
 
 	entities := gameState.gameMap.Layer("Entities")
-	ground := gameState.gameMap.Layer("Ground")
 
-	var targetStairs rune = gmgmap.StairsUp
-	if currentTile == gmgmap.StairsUp {
-		targetStairs = gmgmap.StairsDown
-	}
-
-	structuresX = gameState.gameMap.Layer("Structures")
+	structuresX := gameState.gameMap.Layer("Structures")
 	found := false
 
 	for y := 1; y < gameState.gameMap.Height-1 && !found; y++ {
@@ -438,12 +542,9 @@ func useStairs(g *gocui.Gui, v *gocui.View) error {
 					for dx := -1; dx <= 1 && !found; dx++ {
 						newX, newY := x+dx, y+dy
 						if newX >= 0 && newX+1 < gameState.gameMap.Width && newY >= 0 && newY < gameState.gameMap.Height {
-							groundTile1 := ground.GetTile(newX, newY)
-							groundTile2 := ground.GetTile(newX+1, newY)
 							entityTile1 := entities.GetTile(newX, newY)
 							entityTile2 := entities.GetTile(newX+1, newY)
-							if (groundTile1 == gmgmap.Room || groundTile1 == gmgmap.Room2 || groundTile1 == gmgmap.Floor) &&
-								(groundTile2 == gmgmap.Room || groundTile2 == gmgmap.Room2 || groundTile2 == gmgmap.Floor) &&
+							if isWalkable(gameState.gameMap, newX, newY) && isWalkable(gameState.gameMap, newX+1, newY) &&
 								entityTile1 == gmgmap.Nothing && entityTile2 == gmgmap.Nothing {
 								gameState.playerX = newX
 								gameState.playerY = newY
@@ -458,24 +559,34 @@ func useStairs(g *gocui.Gui, v *gocui.View) error {
 		}
 	}
 
-	if !found {
-		for y := 1; y < gameState.gameMap.Height-1 && y <= 32 && !found; y++ { // Don't spawn below y=32
-			for x := 1; x < gameState.gameMap.Width-2 && !found; x++ { // Leave room for 2-character wide player
-				groundTile1 := ground.GetTile(x, y)
-				groundTile2 := ground.GetTile(x+1, y)
-				entityTile1 := entities.GetTile(x, y)
-				entityTile2 := entities.GetTile(x+1, y)
-				if (groundTile1 == gmgmap.Room || groundTile1 == gmgmap.Room2 || groundTile1 == gmgmap.Floor) &&
-					(groundTile2 == gmgmap.Room || groundTile2 == gmgmap.Room2 || groundTile2 == gmgmap.Floor) &&
-					entityTile1 == gmgmap.Nothing && entityTile2 == gmgmap.Nothing {
-					gameState.playerX = x
-					gameState.playerY = y
-					entities.SetTile(x, y, gmgmap.Player)
-					entities.SetTile(x+1, y, gmgmap.Player) // Player is 2 chars wide
-					found = true
+	if !found { // Stairs crowded or missing, the closest free spot linked to them
+		var stairs, spot []int
+		for y := 0; y < gameState.gameMap.Height && stairs == nil; y++ {
+			for x := 0; x < gameState.gameMap.Width && stairs == nil; x++ {
+				if structuresX.GetTile(x, y) == targetStairs {
+					stairs = standingSpot(gameState.gameMap, x, y)
 				}
 			}
 		}
+		var reached map[[2]int]bool
+		if stairs != nil {
+			reached = reachable(gameState.gameMap, stairs)
+		}
+		for _, s := range freeSpots(gameState.gameMap) {
+			if stairs == nil {
+				spot = s
+				break
+			}
+			if reached[[2]int{s[0], s[1]}] && (spot == nil || distance(s, stairs) < distance(spot, stairs)) {
+				spot = s
+			}
+		}
+		if spot != nil {
+			gameState.playerX = spot[0]
+			gameState.playerY = spot[1]
+			entities.SetTile(spot[0], spot[1], gmgmap.Player)
+			entities.SetTile(spot[0]+1, spot[1], gmgmap.Player) // Player is 2 chars wide
+		}
 	}
 
 	hasEntities := false
@@ -490,8 +601,9 @@ func useStairs(g *gocui.Gui, v *gocui.View) error {
 
 	if !hasEntities {
 		rng := structures.GetRNG()
-		spawnEntities(gameState.gameMap, rng)
+		spawnEntities(gameState.gameMap, gameState.currentLevel, rng)
 	}
+	saveCurrentLevel()
 
 	_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
 
@@ -559,6 +671,10 @@ func createRandomEnemy() *structures.Enemy {
 	name := names[rng.Intn(len(names))]
 
 	enemy := structures.InitScaledEnemy(name, race, dungeonLevel)
+	enemy.Depth = -gameState.currentLevel // Levels go negative going down the stairs
+	if dungeonLevel < 0 && structures.RollElite() {
+		enemy.MakeElite()
+	}
 
 	// Add level-based prefix to enemy name to indicate difficulty
 	if dungeonLevel > 0 {
@@ -584,14 +700,88 @@ func createRandomEnemy() *structures.Enemy {
 var merchant = structures.InitMerchant()
 var blacksmith = structures.InitCraftingBlacksmith()
 
+func handlePlayerDeath() error {
+	ui.ClearScreen()
+	fmt.Println("=== GAME OVER ===")
+	fmt.Printf("Your character %s has fallen in battle!\n", gameState.player.Entity.Name)
+	fmt.Println()
+	fmt.Println("You can respawn with 50% health, but you'll lose all your equipment.")
+	fmt.Println("Items left in the town stash are safe.")
+	fmt.Println("Press Enter to respawn or Esc to quit...")
+
+	if handleRespawnChoice() {
+		gameState.currentLevel = 0
+		respawnPlayer(gameState.player)
+		_ = save.SaveAny("player", gameState.player)
+
+		chests = map[int][]structures.Chest{} // The dungeon is generated anew, so are its chests
+		portal = townPortal{}
+		mappedLevels = map[int]bool{}
+		saveScrollState()
+		explored = map[int][][]bool{}
+		seenMobs = map[int]map[[2]int]bool{}
+		mobStates = map[int]map[[2]int]*mobState{}
+		detectedTraps = map[int]map[[2]int]bool{}
+		saveTraps()
+		markers = map[int][]mapMarker{}
+		saveMarkers()
+		floorLoot = map[int][]lootPile{}
+		saveFloorLoot()
+		deleteSavedLevels()
+		rng := structures.GetRNG()
+		freshMap := generateMapForLevel(0, rng)
+		spawnEntities(freshMap, 0, rng)
+		saveLevel(0, freshMap)
+
+		gameState.maps = map[int]*gmgmap.Map{0: freshMap}
+		gameState.currentLevel = 0
+		gameState.gameMap = freshMap
+
+		px, py := findPlayer(freshMap)
+		if px == -1 || py == -1 {
+			px, py = 1, 1
+			entities := freshMap.Layer("Entities")
+			entities.SetTile(px, py, gmgmap.Player)
+			if px+1 < freshMap.Width {
+				entities.SetTile(px+1, py, gmgmap.Player)
+			}
+		}
+		gameState.playerX = px
+		gameState.playerY = py
+		_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
+
+		ui.ClearScreen()
+		fmt.Println("You have been revived! You wake up at the entrance with only the basics...")
+		fmt.Println("Press Enter to continue...")
+		fmt.Scanln()
+
+		ui.ClearScreen()
+		return restartGameLoop()
+	} else {
+		return gocui.ErrQuit
+	}
+}
+
 func tryMove(g *gocui.Gui, dx, dy int) error {
-	if gameState == nil {
+	if gameState == nil || fullMapOpen {
+		return nil
+	}
+
+	if delay := gameState.player.Encumbrance().MoveDelay; delay > 0 && time.Since(gameState.lastMove) < delay {
 		return nil
 	}
+	gameState.lastMove = time.Now()
 
 	newX := gameState.playerX + dx
 	newY := gameState.playerY + dy
 
+	if opened, err := bumpDoor(g, newX, newY); opened {
+		return err
+	}
+	if handled, err := bumpTrap(g, newX, newY); handled {
+		return err
+	}
+
 	if canMoveTo(gameState.gameMap, newX, newY) {
 		entities := gameState.gameMap.Layer("Entities")
 
@@ -602,14 +792,6 @@ func tryMove(g *gocui.Gui, dx, dy int) error {
 		}
 
 		if entityTile1 == gmgmap.Mob || entityTile2 == gmgmap.Mob {
-			var enemy *structures.Enemy
-			if gameState.currentLevel < 0 && (gameState.currentLevel%3) == 0 {
-				boss := structures.InitBoss("Ash of the Forgotten", "Orc")
-				enemy = &boss
-			} else {
-				enemy = createRandomEnemy()
-			}
-
 			for cx := newX - 1; cx <= newX+2; cx++ {
 				if cx >= 0 && cx < gameState.gameMap.Width {
 					if entities.GetTile(cx, newY) == gmgmap.Mob {
@@ -617,140 +799,38 @@ func tryMove(g *gocui.Gui, dx, dy int) error {
 					}
 				}
 			}
+			return fightMob(g, newX, newY, false)
+		}
 
+		if entityTile1 == gmgmap.Merchant || entityTile2 == gmgmap.Merchant {
 			g.Close()
 			ui.ClearScreen()
 
-			_ = fight.StartFight(gameState.player, enemy)
+			ui.ShowMerchantMenu(merchant, gameState.player)
 
-			if !gameState.player.Entity.Alive {
-				ui.ClearScreen()
-				fmt.Println("=== GAME OVER ===")
-				fmt.Printf("Your character %s has fallen in battle!\n", gameState.player.Entity.Name)
-				fmt.Println()
-				fmt.Println("You can respawn with 50% health, but you'll lose all your equipment.")
-				fmt.Println("Press Enter to respawn or Esc to quit...")
-
-				if handleRespawnChoice() {
-					gameState.currentLevel = 0
-					respawnPlayer(gameState.player)
-					_ = save.SaveAny("player", gameState.player)
-
-					rng := structures.GetRNG()
-					freshMap := generateMapForLevel(0, rng)
-					spawnEntities(freshMap, rng)
-
-					gameState.maps = map[int]*gmgmap.Map{0: freshMap}
-					gameState.currentLevel = 0
-					gameState.gameMap = freshMap
-
-					px, py := findPlayer(freshMap)
-					if px == -1 || py == -1 {
-						px, py = 1, 1
-						entities := freshMap.Layer("Entities")
-						entities.SetTile(px, py, gmgmap.Player)
-						if px+1 < freshMap.Width {
-							entities.SetTile(px+1, py, gmgmap.Player)
-						}
-					}
-					gameState.playerX = px
-					gameState.playerY = py
-					_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
-
-					ui.ClearScreen()
-					fmt.Println("You have been revived! You wake up at the entrance with only the basics...")
-					fmt.Println("Press Enter to continue...")
-					fmt.Scanln()
-
-					ui.ClearScreen()
-					return restartGameLoop()
-				} else {
-					return gocui.ErrQuit
-				}
-			}
+			save.SaveAny("player", gameState.player)
 
-			movePlayer(gameState.gameMap, gameState.playerX, gameState.playerY, newX, newY)
-			gameState.playerX = newX
-			gameState.playerY = newY
-			_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
-
-			if enemy.IsBoss && gameState.player.Entity.Alive {
-				minDist := 10
-				structuresLayer := gameState.gameMap.Layer("Structures")
-				ground := gameState.gameMap.Layer("Ground")
-				entitiesLayer := gameState.gameMap.Layer("Entities")
-				placed := false
-				maxY := gameState.gameMap.Height
-				if maxY > 33 { // Keep within visible area (y <= 32)
-					maxY = 33
-				}
-				for y := 0; y < maxY && !placed; y++ {
-					for x := 0; x < gameState.gameMap.Width-1 && !placed; x++ {
-						dx := x - gameState.playerX
-						if dx < 0 {
-							dx = -dx
-						}
-						dy := y - gameState.playerY
-						if dy < 0 {
-							dy = -dy
-						}
-						if dx+dy < minDist {
-							continue
-						}
-						g1 := ground.GetTile(x, y)
-						g2 := ground.GetTile(x+1, y)
-						e1 := entitiesLayer.GetTile(x, y)
-						e2 := entitiesLayer.GetTile(x+1, y)
-						s1 := structuresLayer.GetTile(x, y)
-						s2 := structuresLayer.GetTile(x+1, y)
-						validGround := (g1 == gmgmap.Room || g1 == gmgmap.Room2 || g1 == gmgmap.Floor) &&
-							(g2 == gmgmap.Room || g2 == gmgmap.Room2 || g2 == gmgmap.Floor)
-						if !validGround {
-							continue
-						}
-						if e1 != gmgmap.Nothing || e2 != gmgmap.Nothing {
-							continue
-						}
-						if s1 != gmgmap.Nothing || s2 != gmgmap.Nothing {
-							continue
-						}
-						structuresLayer.SetTile(x, y, gmgmap.StairsDown)
-						fmt.Printf("Stairs spawned at: (%d, %d)\n", x, y)
-						placed = true
-					}
-				}
-				// Fallback near top center if no position found (should be visible and reachable)
-				if !placed {
-					fx := gameState.gameMap.Width/2 - 1
-					if fx < 0 {
-						fx = 0
-					}
-					fy := 2
-					// Ensure empty and valid ground
-					g1 := ground.GetTile(fx, fy)
-					g2 := ground.GetTile(fx+1, fy)
-					e1 := entitiesLayer.GetTile(fx, fy)
-					e2 := entitiesLayer.GetTile(fx+1, fy)
-					s1 := structuresLayer.GetTile(fx, fy)
-					s2 := structuresLayer.GetTile(fx+1, fy)
-					validGround := (g1 == gmgmap.Room || g1 == gmgmap.Room2 || g1 == gmgmap.Floor) &&
-						(g2 == gmgmap.Room || g2 == gmgmap.Room2 || g2 == gmgmap.Floor)
-					if e1 == gmgmap.Nothing && e2 == gmgmap.Nothing && s1 == gmgmap.Nothing && s2 == gmgmap.Nothing && validGround {
-						structuresLayer.SetTile(fx, fy, gmgmap.StairsDown)
-						fmt.Printf("Stairs spawned at: (%d, %d) [fallback]\n", fx, fy)
-					}
-				}
-			}
+			ui.ClearScreen()
+			return restartGameLoop()
+		}
+
+		if entityTile1 == gmgmap.Blacksmith || entityTile2 == gmgmap.Blacksmith {
+			g.Close()
+			ui.ClearScreen()
+
+			ui.ShowBlacksmithMenu(blacksmith, gameState.player)
+
+			save.SaveAny("player", gameState.player)
 
 			ui.ClearScreen()
 			return restartGameLoop()
 		}
 
-		if entityTile1 == gmgmap.Merchant || entityTile2 == gmgmap.Merchant {
+		if entityTile1 == gmgmap.Alchemist || entityTile2 == gmgmap.Alchemist {
 			g.Close()
 			ui.ClearScreen()
 
-			ui.ShowMerchantMenu(merchant, gameState.player)
+			ui.ShowAlchemyMenu(gameState.player)
 
 			save.SaveAny("player", gameState.player)
 
@@ -758,18 +838,71 @@ func tryMove(g *gocui.Gui, dx, dy int) error {
 			return restartGameLoop()
 		}
 
-		if entityTile1 == gmgmap.Blacksmith || entityTile2 == gmgmap.Blacksmith {
+		if entityTile1 == gmgmap.Stash || entityTile2 == gmgmap.Stash {
 			g.Close()
 			ui.ClearScreen()
 
-			ui.ShowBlacksmithMenu(blacksmith, gameState.player)
+			stash := structures.Stash{}
+			_ = save.LoadAny("stash", &stash)
+			shared := structures.Stash{}
+			_ = save.LoadShared("stash", &shared)
+
+			ui.ShowStashMenu(gameState.player, &stash, &shared)
+
+			save.SaveAny("stash", stash)
+			save.SaveShared("stash", shared)
+			save.SaveAny("player", gameState.player)
+
+			ui.ClearScreen()
+			return restartGameLoop()
+		}
+
+		if entityTile1 == gmgmap.Portal || entityTile2 == gmgmap.Portal {
+			return usePortal(g)
+		}
+
+		if entityTile1 == gmgmap.LootPile || entityTile2 == gmgmap.LootPile {
+			index := findPile(gameState.currentLevel, newX, newY)
+			if index == -1 {
+				return nil
+			}
+			g.Close()
+			ui.ClearScreen()
+
+			pickUpPile(gameState.gameMap, gameState.player, gameState.currentLevel, index)
+			saveCurrentLevel()
+			save.SaveAny("player", gameState.player)
+
+			ui.ClearScreen()
+			return restartGameLoop()
+		}
+
+		if entityTile1 == gmgmap.Chest || entityTile2 == gmgmap.Chest {
+			chest := findChest(gameState.currentLevel, newX, newY)
+			if chest == nil {
+				return nil
+			}
+			g.Close()
+			ui.ClearScreen()
+
+			openChest(gameState.player, chest, -gameState.currentLevel)
+			if !gameState.player.Entity.Alive {
+				return handlePlayerDeath()
+			}
+			if chest.Opened {
+				removeChestTile(gameState.gameMap, chest)
+			}
+			dropLoot(gameState.gameMap, gameState.currentLevel, gameState.playerX, gameState.playerY, gameState.player.TakeDropped())
 
+			saveChests()
+			saveCurrentLevel()
 			save.SaveAny("player", gameState.player)
 
 			ui.ClearScreen()
 			return restartGameLoop()
 		}
 
+		oldX, oldY := gameState.playerX, gameState.playerY
 		movePlayer(gameState.gameMap, gameState.playerX, gameState.playerY, newX, newY)
 		gameState.playerX = newX
 		gameState.playerY = newY
@@ -784,6 +917,19 @@ func tryMove(g *gocui.Gui, dx, dy int) error {
 		if gmgmap.IsStairs(leftTile) || gmgmap.IsStairs(rightTile) {
 			return useStairs(g, nil)
 		}
+		if used, err := useShopDoor(g); used {
+			return err
+		}
+		if triggered, err := stepOnTrap(g, oldX, oldY); triggered {
+			return err
+		}
+		noticeTraps()
+		if died, err := tickEffects(g); died {
+			return err
+		}
+		if fought, err := moveMobs(g); fought {
+			return err
+		}
 
 		g.Update(func(g *gocui.Gui) error {
 			gameView, _ := g.View("game")
@@ -801,6 +947,105 @@ func tryMove(g *gocui.Gui, dx, dy int) error {
 	return nil
 }
 
+// Fight with a mob already taken off the map, the player then stands at x,y
+func fightMob(g *gocui.Gui, x, y int, ambush bool) error {
+	var enemy *structures.Enemy
+	if gameState.currentLevel < 0 && (gameState.currentLevel%3) == 0 {
+		boss := structures.InitBoss("Ash of the Forgotten", "Orc")
+		boss.Depth = -gameState.currentLevel
+		enemy = &boss
+	} else {
+		enemy = createRandomEnemy()
+	}
+
+	g.Close()
+	ui.ClearScreen()
+
+	if ambush {
+		_ = fight.StartAmbush(gameState.player, enemy)
+	} else {
+		_ = fight.StartFight(gameState.player, enemy)
+	}
+
+	if !gameState.player.Entity.Alive {
+		return handlePlayerDeath()
+	}
+
+	movePlayer(gameState.gameMap, gameState.playerX, gameState.playerY, x, y)
+	gameState.playerX = x
+	gameState.playerY = y
+	_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
+	dropLoot(gameState.gameMap, gameState.currentLevel, x, y, gameState.player.TakeDropped())
+
+	if enemy.IsBoss && gameState.player.Entity.Alive {
+		minDist := 10
+		structuresLayer := gameState.gameMap.Layer("Structures")
+		ground := gameState.gameMap.Layer("Ground")
+		entitiesLayer := gameState.gameMap.Layer("Entities")
+		placed := false
+		for y := 0; y < gameState.gameMap.Height && !placed; y++ {
+			for x := 0; x < gameState.gameMap.Width-1 && !placed; x++ {
+				dx := x - gameState.playerX
+				if dx < 0 {
+					dx = -dx
+				}
+				dy := y - gameState.playerY
+				if dy < 0 {
+					dy = -dy
+				}
+				if dx+dy < minDist {
+					continue
+				}
+				g1 := ground.GetTile(x, y)
+				g2 := ground.GetTile(x+1, y)
+				e1 := entitiesLayer.GetTile(x, y)
+				e2 := entitiesLayer.GetTile(x+1, y)
+				s1 := structuresLayer.GetTile(x, y)
+				s2 := structuresLayer.GetTile(x+1, y)
+				validGround := (g1 == gmgmap.Room || g1 == gmgmap.Room2 || g1 == gmgmap.Floor) &&
+					(g2 == gmgmap.Room || g2 == gmgmap.Room2 || g2 == gmgmap.Floor)
+				if !validGround {
+					continue
+				}
+				if e1 != gmgmap.Nothing || e2 != gmgmap.Nothing {
+					continue
+				}
+				if s1 != gmgmap.Nothing || s2 != gmgmap.Nothing {
+					continue
+				}
+				structuresLayer.SetTile(x, y, gmgmap.StairsDown)
+				fmt.Printf("Stairs spawned at: (%d, %d)\n", x, y)
+				placed = true
+			}
+		}
+		// Fallback near top center if no position found (should be visible and reachable)
+		if !placed {
+			fx := gameState.gameMap.Width/2 - 1
+			if fx < 0 {
+				fx = 0
+			}
+			fy := 2
+			// Ensure empty and valid ground
+			g1 := ground.GetTile(fx, fy)
+			g2 := ground.GetTile(fx+1, fy)
+			e1 := entitiesLayer.GetTile(fx, fy)
+			e2 := entitiesLayer.GetTile(fx+1, fy)
+			s1 := structuresLayer.GetTile(fx, fy)
+			s2 := structuresLayer.GetTile(fx+1, fy)
+			validGround := (g1 == gmgmap.Room || g1 == gmgmap.Room2 || g1 == gmgmap.Floor) &&
+				(g2 == gmgmap.Room || g2 == gmgmap.Room2 || g2 == gmgmap.Floor)
+			if e1 == gmgmap.Nothing && e2 == gmgmap.Nothing && s1 == gmgmap.Nothing && s2 == gmgmap.Nothing && validGround {
+				structuresLayer.SetTile(fx, fy, gmgmap.StairsDown)
+				fmt.Printf("Stairs spawned at: (%d, %d) [fallback]\n", fx, fy)
+			}
+		}
+	}
+	saveCurrentLevel()
+
+	ui.ClearScreen()
+	return restartGameLoop()
+}
+
 func setupKeybindings(g *gocui.Gui) error {
 	if err := g.SetKeybinding("", 'z', gocui.ModNone, moveUp); err != nil {
 		return err
@@ -845,8 +1090,20 @@ func setupKeybindings(g *gocui.Gui) error {
 	if err := g.SetKeybinding("", gocui.KeyEsc, gocui.ModNone, exitGame); err != nil {
 		return err
 	}
+	if err := g.SetKeybinding("", 'o', gocui.ModNone, toggleDoor); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("", 'O', gocui.ModNone, toggleDoor); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("", 't', gocui.ModNone, searchTraps); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("", 'T', gocui.ModNone, searchTraps); err != nil {
+		return err
+	}
 
-	return nil
+	return setupMapKeybindings(g)
 }
 
 func restartGameLoop() error {
@@ -915,8 +1172,27 @@ func StartGame(username, race, seedStr string) {
 	fmt.Printf("Starting game for %s (%s) with seed %s\n", username, race, seedStr)
 	rng := structures.GetRNG()
 
-	m := generateMapForLevel(0, rng)
-	spawnEntities(m, rng)
+	loadChests()
+	loadScrollState()
+	loadFloorLoot()
+	loadShopDoors()
+	loadMarkers()
+	loadTraps()
+	explored = map[int][][]bool{} // Read back from the level saves of this character when drawn
+	seenMobs = map[int]map[[2]int]bool{}
+	mobStates = map[int]map[[2]int]*mobState{}
+	ui.ReadScroll = readScroll
+	m := loadLevel(0)
+	if m == nil {
+		m = generateMapForLevel(0, rng)
+		spawnEntities(m, 0, rng)
+		saveLevel(0, m)
+	} else if px, _ := findPlayer(m); px == -1 {
+		if spot, ok := townStart(m); ok { // The player left town, the saved world state moves them back
+			m.Layer("Entities").SetTile(spot[0], spot[1], gmgmap.Player)
+			m.Layer("Entities").SetTile(spot[0]+1, spot[1], gmgmap.Player)
+		}
+	}
 
 	player := structures.InitCharacter(username, race)
 
@@ -927,7 +1203,7 @@ func StartGame(username, race, seedStr string) {
 	}
 
 	fmt.Println("Starting game... Use ZQSD to move, F to use stairs, ESC for menu")
-	fmt.Println("Walk over merchants/blacksmiths to interact with them")
+	fmt.Println("Walk over merchants/blacksmiths/alchemists to interact with them")
 
 	for {
 		err := startGameLoopWithPlayer(m, &player)
@@ -960,21 +1236,15 @@ func startGameLoopWithPlayer(m *gmgmap.Map, player *structures.Player) error {
 	}
 
 	if ws, err := save.LoadWorldState(); err == nil {
-		if ws.CurrentLevel <= 0 {
-			if gameState.maps[ws.CurrentLevel] == nil {
-				rng := structures.GetRNG()
-				gameState.maps[ws.CurrentLevel] = generateMapForLevel(ws.CurrentLevel, rng)
+		if ws.CurrentLevel <= len(shops) {
+			if ws.CurrentLevel != 0 {
+				clearPlayerTiles(m) // The player is on the saved level, not in town
 			}
+			gameState.maps[ws.CurrentLevel] = levelMap(ws.CurrentLevel)
 			gameState.currentLevel = ws.CurrentLevel
 			gameState.gameMap = gameState.maps[ws.CurrentLevel]
 			entities := gameState.gameMap.Layer("Entities")
-			for y := 0; y < gameState.gameMap.Height; y++ {
-				for x := 0; x < gameState.gameMap.Width; x++ {
-					if entities.GetTile(x, y) == gmgmap.Player {
-						entities.SetTile(x, y, gmgmap.Nothing)
-					}
-				}
-			}
+			clearPlayerTiles(gameState.gameMap)
 			gameState.playerX = ws.PlayerX
 			gameState.playerY = ws.PlayerY
 			if gameState.playerX+1 < gameState.gameMap.Width {
@@ -993,8 +1263,9 @@ func startGameLoopWithPlayer(m *gmgmap.Map, player *structures.Player) error {
 			}
 			if !hasEntities {
 				rng := structures.GetRNG()
-				spawnEntities(gameState.gameMap, rng)
+				spawnEntities(gameState.gameMap, gameState.currentLevel, rng)
 			}
+			saveCurrentLevel()
 		}
 	}
 
@@ -1071,6 +1342,18 @@ func setupKeybindingsWithPlayer(g *gocui.Gui) error {
 	if err := g.SetKeybinding("", gocui.KeyEsc, gocui.ModNone, exitGame); err != nil {
 		return err
 	}
+	if err := g.SetKeybinding("", 'o', gocui.ModNone, toggleDoor); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("", 'O', gocui.ModNone, toggleDoor); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("", 't', gocui.ModNone, searchTraps); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("", 'T', gocui.ModNone, searchTraps); err != nil {
+		return err
+	}
 
-	return nil
+	return setupMapKeybindings(g)
 }
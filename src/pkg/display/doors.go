@@ -0,0 +1,289 @@
+package display
+
+import (
+	"fmt"
+	"math/rand"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+	"main/pkg/ui"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Smallest and largest share of the level a vault can take
+const (
+	minVaultSpots    = 6
+	maxVaultFraction = 4
+)
+
+// The interior generator leaves openings between its rooms and corridors, they become closed doors
+// An opening is a corridor tile in a room wall, with the room on one side and the corridor on the other
+func openingsToDoors(m *gmgmap.Map) *gmgmap.Map {
+	ground := m.Layer("Ground")
+	structuresLayer := m.Layer("Structures")
+	isOpening := func(x, y, dx, dy int) bool {
+		side1, side2 := ground.GetTile(x-dx, y-dy), ground.GetTile(x+dx, y+dy)
+		return (side1 == gmgmap.Room && side2 == gmgmap.Room2 || side1 == gmgmap.Room2 && side2 == gmgmap.Room) &&
+			(gmgmap.IsWall(structuresLayer.GetTile(x-dy, y-dx)) || gmgmap.IsWall(structuresLayer.GetTile(x+dy, y+dx)))
+	}
+	var doors [][2]int
+	for y := 1; y < m.Height-1; y++ {
+		for x := 1; x < m.Width-1; x++ {
+			if ground.GetTile(x, y) != gmgmap.Room2 || structuresLayer.GetTile(x, y) != gmgmap.Nothing {
+				continue
+			}
+			if isOpening(x, y, 1, 0) || isOpening(x, y, 0, 1) {
+				doors = append(doors, [2]int{x, y})
+			}
+		}
+	}
+	for _, d := range doors {
+		structuresLayer.SetTile(d[0], d[1], gmgmap.Door)
+	}
+	return m
+}
+
+// Dungeon doors block the way and the sight until opened, town and shop doors are only passages
+func isClosedDoor(m *gmgmap.Map, x, y int) bool {
+	if gameState == nil || gameState.currentLevel >= 0 {
+		return false
+	}
+	tile := m.Layer("Structures").GetTile(x, y)
+	return tile == gmgmap.Door || tile == gmgmap.DoorLocked
+}
+
+// Door tiles touching x,y, a door is 2 tiles wide or tall
+func doorGroup(m *gmgmap.Map, x, y int) [][2]int {
+	structuresLayer := m.Layer("Structures")
+	if !gmgmap.IsDoor(structuresLayer.GetTile(x, y)) {
+		return nil
+	}
+	seen := map[[2]int]bool{{x, y}: true}
+	group := [][2]int{{x, y}}
+	for i := 0; i < len(group); i++ {
+		for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			n := [2]int{group[i][0] + d[0], group[i][1] + d[1]}
+			if !seen[n] && n[0] >= 0 && n[0] < m.Width && n[1] >= 0 && n[1] < m.Height && gmgmap.IsDoor(structuresLayer.GetTile(n[0], n[1])) {
+				seen[n] = true
+				group = append(group, n)
+			}
+		}
+	}
+	return group
+}
+
+func setDoor(m *gmgmap.Map, group [][2]int, tile rune) {
+	structuresLayer := m.Layer("Structures")
+	for _, t := range group {
+		structuresLayer.SetTile(t[0], t[1], tile)
+	}
+}
+
+// Walking into a closed door opens it, locked ones need a key or a good shoulder
+func bumpDoor(g *gocui.Gui, x, y int) (bool, error) {
+	m := gameState.gameMap
+	for i := 0; i < 2; i++ {
+		if !isClosedDoor(m, x+i, y) {
+			continue
+		}
+		group := doorGroup(m, x+i, y)
+		if m.Layer("Structures").GetTile(x+i, y) == gmgmap.DoorLocked {
+			return true, unlockDoor(g, group)
+		}
+		setDoor(m, group, gmgmap.DoorOpen)
+		saveCurrentLevel()
+		return true, endTurn(g)
+	}
+	return false, nil
+}
+
+// Opens or closes the doors next to the player, a door with something in the way stays open
+func toggleDoor(g *gocui.Gui, v *gocui.View) error {
+	if gameState == nil || gameState.currentLevel >= 0 || fullMapOpen {
+		return nil
+	}
+	m := gameState.gameMap
+	structuresLayer := m.Layer("Structures")
+	entities := m.Layer("Entities")
+	var open, closed [][2]int
+	for y := gameState.playerY - 1; y <= gameState.playerY+1; y++ {
+		for x := gameState.playerX - 1; x <= gameState.playerX+2; x++ {
+			switch structuresLayer.GetTile(x, y) {
+			case gmgmap.DoorOpen:
+				open = append(open, [2]int{x, y})
+			case gmgmap.Door:
+				closed = append(closed, [2]int{x, y})
+			}
+		}
+	}
+
+	toggled := false
+	for _, spot := range open {
+		group := doorGroup(m, spot[0], spot[1])
+		blocked := false
+		for _, t := range group {
+			if entities.GetTile(t[0], t[1]) != gmgmap.Nothing {
+				blocked = true
+			}
+		}
+		if !blocked {
+			setDoor(m, group, gmgmap.Door)
+			toggled = true
+		}
+	}
+	if !toggled {
+		for _, spot := range closed {
+			setDoor(m, doorGroup(m, spot[0], spot[1]), gmgmap.DoorOpen)
+			toggled = true
+		}
+	}
+	if !toggled {
+		return nil
+	}
+	saveCurrentLevel()
+	return endTurn(g)
+}
+
+func unlockDoor(g *gocui.Gui, group [][2]int) error {
+	player := gameState.player
+	g.Close()
+	ui.ClearScreen()
+
+	fmt.Println("The door is locked.")
+	opened := false
+	if player.HasVaultKey() && askYesNo(fmt.Sprintf("Use a %s?", structures.VaultKey.Name)) {
+		player.RemoveMaterials(structures.VaultKey.Key, 1)
+		fmt.Println("The heavy lock turns and the door swings open.")
+		opened = true
+	} else if askYesNo(fmt.Sprintf("Try to bash it open? (%d%% chance, it hurts if it holds)", player.BashChance())) {
+		broken, damage := player.BashDoor()
+		if broken {
+			fmt.Println("The door bursts open!")
+			opened = true
+		} else {
+			fmt.Printf("The door holds. You take %d damage.\n", damage)
+		}
+		if !player.Entity.Alive {
+			return handlePlayerDeath()
+		}
+	}
+	if opened {
+		setDoor(gameState.gameMap, group, gmgmap.DoorOpen)
+		saveCurrentLevel()
+	}
+	save.SaveAny("player", player)
+	fmt.Println("Press Enter to continue...")
+	fmt.Scanln()
+
+	ui.ClearScreen()
+	return restartGameLoop()
+}
+
+// Locks a door that closes off a small part of the level, away from the stairs, and returns a spot for the vault chest
+// along with the spots behind the door
+func makeVault(m *gmgmap.Map, rng *rand.Rand) ([]int, map[[2]int]bool, bool) {
+	structuresLayer := m.Layer("Structures")
+	var stairs [][]int
+	groups := map[[2]int][][2]int{}
+	var doors [][2]int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			tile := structuresLayer.GetTile(x, y)
+			if gmgmap.IsStairs(tile) {
+				stairs = append(stairs, []int{x, y})
+			}
+			if tile == gmgmap.Door && groups[[2]int{x, y}] == nil {
+				group := doorGroup(m, x, y)
+				for _, t := range group {
+					groups[t] = group
+				}
+				doors = append(doors, [2]int{x, y})
+			}
+		}
+	}
+	if len(stairs) == 0 || len(doors) == 0 {
+		return nil, nil, false
+	}
+	rng.Shuffle(len(doors), func(i, j int) { doors[i], doors[j] = doors[j], doors[i] })
+
+	// The player stands on the stairs with one of their 2 tiles
+	stairsReached := func(reached map[[2]int]bool) bool {
+		for _, s := range stairs {
+			if !reached[[2]int{s[0], s[1]}] && !reached[[2]int{s[0] - 1, s[1]}] {
+				return false
+			}
+		}
+		return true
+	}
+	start := stairs[0]
+	if !isWalkable(m, start[0]+1, start[1]) {
+		start = []int{start[0] - 1, start[1]}
+	}
+	whole := reachable(m, start)
+	for _, door := range doors {
+		group := groups[door]
+		setDoor(m, group, gmgmap.Wall)
+		outside := reachable(m, start)
+		setDoor(m, group, gmgmap.Door)
+		vaultSize := len(whole) - len(outside)
+		if !stairsReached(outside) || vaultSize < minVaultSpots || vaultSize > len(whole)/maxVaultFraction {
+			continue
+		}
+
+		// The chest goes to the back of the vault
+		var chestSpot []int
+		for spot := range whole {
+			if outside[spot] || !chestSpotFree(m, spot[0], spot[1]) || structuresLayer.GetTile(spot[0], spot[1]) != gmgmap.Nothing || structuresLayer.GetTile(spot[0]+1, spot[1]) != gmgmap.Nothing {
+				continue
+			}
+			candidate := []int{spot[0], spot[1]}
+			if chestSpot == nil || distance(candidate, door[:]) > distance(chestSpot, door[:]) ||
+				distance(candidate, door[:]) == distance(chestSpot, door[:]) && (candidate[1] < chestSpot[1] || candidate[1] == chestSpot[1] && candidate[0] < chestSpot[0]) {
+				chestSpot = candidate
+			}
+		}
+		if chestSpot == nil {
+			continue
+		}
+		setDoor(m, group, gmgmap.DoorLocked)
+		vault := map[[2]int]bool{}
+		for spot := range whole {
+			if !outside[spot] {
+				vault[spot] = true
+			}
+		}
+		return chestSpot, vault, true
+	}
+	return nil, nil, false
+}
+
+// Spots behind the locked vault door, none once it is opened
+func vaultArea(m *gmgmap.Map) map[[2]int]bool {
+	structuresLayer := m.Layer("Structures")
+	var start []int
+	locked := false
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			tile := structuresLayer.GetTile(x, y)
+			if gmgmap.IsStairs(tile) && start == nil {
+				start = standingSpot(m, x, y)
+			}
+			if tile == gmgmap.DoorLocked {
+				locked = true
+			}
+		}
+	}
+	if !locked || start == nil {
+		return nil
+	}
+	outside := reachableWith(start, spotPassable(m))
+	vault := map[[2]int]bool{}
+	for spot := range reachable(m, start) {
+		if !outside[spot] {
+			vault[spot] = true
+		}
+	}
+	return vault
+}
@@ -0,0 +1,93 @@
+package display
+
+import (
+	"fmt"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+)
+
+// Items that were too heavy to carry, left on the floor to be picked up later
+type lootPile struct {
+	X, Y  int
+	Items structures.Inventory
+}
+
+var floorLoot = map[int][]lootPile{}
+
+func loadFloorLoot() {
+	floorLoot = map[int][]lootPile{}
+	_ = save.LoadAny("floor_loot", &floorLoot)
+}
+
+func saveFloorLoot() {
+	_ = save.SaveAny("floor_loot", floorLoot)
+}
+
+func setPileTile(m *gmgmap.Map, x, y int, tile rune) {
+	entities := m.Layer("Entities")
+	entities.SetTile(x, y, tile)
+	entities.SetTile(x+1, y, tile) // Piles are 2 chars wide
+}
+
+// Drops the items next to x,y on the current level
+func dropLoot(m *gmgmap.Map, level, x, y int, items structures.Inventory) {
+	if len(items) == 0 {
+		return
+	}
+	spot, ok := nearbySpot(m, x, y)
+	if !ok {
+		return
+	}
+	floorLoot[level] = append(floorLoot[level], lootPile{X: spot[0], Y: spot[1], Items: items})
+	setPileTile(m, spot[0], spot[1], gmgmap.LootPile)
+	saveFloorLoot()
+}
+
+// Puts back the piles of a freshly generated level, moved if something took their spot
+func placeFloorLoot(m *gmgmap.Map, level int) {
+	entities := m.Layer("Entities")
+	for i := range floorLoot[level] {
+		pile := &floorLoot[level][i]
+		if !canMoveTo(m, pile.X, pile.Y) || entities.GetTile(pile.X, pile.Y) != gmgmap.Nothing || entities.GetTile(pile.X+1, pile.Y) != gmgmap.Nothing {
+			spot, ok := nearbySpot(m, pile.X, pile.Y)
+			if !ok {
+				continue
+			}
+			pile.X, pile.Y = spot[0], spot[1]
+		}
+		setPileTile(m, pile.X, pile.Y, gmgmap.LootPile)
+	}
+}
+
+// Finds the pile the player walked into, the player and the pile are both 2 chars wide
+func findPile(level, x, y int) int {
+	for i, pile := range floorLoot[level] {
+		if pile.Y == y && pile.X >= x-1 && pile.X <= x+1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Picks up what fits in the bag, the rest stays on the floor
+func pickUpPile(m *gmgmap.Map, player *structures.Player, level, index int) {
+	pile := &floorLoot[level][index]
+	received, left := player.AddItems(pile.Items)
+	pile.Items = left
+
+	fmt.Println("You search the pile on the floor:")
+	for _, entry := range received {
+		fmt.Printf("  + %s\n", entry.GetItem().Name)
+	}
+	if len(left) > 0 {
+		fmt.Printf("Still too heavy, %d item(s) stay on the floor.\n", len(left))
+	} else {
+		setPileTile(m, pile.X, pile.Y, gmgmap.Nothing)
+		floorLoot[level] = append(floorLoot[level][:index], floorLoot[level][index+1:]...)
+	}
+	saveFloorLoot()
+	fmt.Println("Press Enter to continue...")
+	fmt.Scanln()
+}
@@ -0,0 +1,135 @@
+package display
+
+import (
+	"regexp"
+	"strings"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+
+	"github.com/fatih/color"
+)
+
+// How far the player sees in the dungeon, in rows. A char is about half as wide as tall,
+// so the player sees twice as many columns. The town and the shops are always lit.
+var LightRadius = 8
+
+// Tiles the player has seen, by level
+var explored = map[int][][]bool{}
+
+func exploredSaveName(level int) string {
+	return levelSaveName(level) + "_explored"
+}
+
+func exploredMask(level int, m *gmgmap.Map) [][]bool {
+	if mask, ok := explored[level]; ok && len(mask) == m.Height {
+		return mask
+	}
+	mask := make([][]bool, m.Height)
+	for y := range mask {
+		mask[y] = make([]bool, m.Width)
+	}
+	var rows []string
+	if err := save.LoadAny(exploredSaveName(level), &rows); err == nil && len(rows) == m.Height {
+		for y, row := range rows {
+			for x := 0; x < len(row) && x < m.Width; x++ {
+				mask[y][x] = row[x] == '#'
+			}
+		}
+	}
+	explored[level] = mask
+	return mask
+}
+
+// Saved as text rows, '#' for explored tiles
+func saveExplored(level int) {
+	mask, ok := explored[level]
+	if !ok {
+		return
+	}
+	rows := make([]string, len(mask))
+	for y, row := range mask {
+		var sb strings.Builder
+		for _, seen := range row {
+			if seen {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		rows[y] = sb.String()
+	}
+	_ = save.SaveAny(exploredSaveName(level), rows)
+}
+
+func isLit(level int) bool {
+	return level >= 0
+}
+
+// Tiles in sight of the player, they are marked as explored on the way
+func visibleTiles(m *gmgmap.Map, level, px, py int) [][]bool {
+	mask := exploredMask(level, m)
+	visible := make([][]bool, m.Height)
+	for y := range visible {
+		visible[y] = make([]bool, m.Width)
+	}
+	r := LightRadius
+	for dy := -r; dy <= r; dy++ {
+		for dx := -2 * r; dx <= 2*r+1; dx++ {
+			x, y := px+dx, py+dy
+			if x < 0 || x >= m.Width || y < 0 || y >= m.Height || dx*dx+4*dy*dy > 4*r*r {
+				continue
+			}
+			// The player is 2 chars wide, each half has its own line of sight
+			if lineOfSight(m, px, py, x, y) || lineOfSight(m, px+1, py, x, y) {
+				visible[y][x] = true
+				mask[y][x] = true
+				rememberMob(level, x, y, m.Layer("Entities").GetTile(x, y) == gmgmap.Mob)
+			}
+		}
+	}
+	return visible
+}
+
+// Bresenham line, walls and closed doors block the tiles behind them but are seen themselves
+func lineOfSight(m *gmgmap.Map, x0, y0, x1, y1 int) bool {
+	structuresLayer := m.Layer("Structures")
+	dx, dy := x1-x0, y1-y0
+	sx, sy := 1, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+	err := dx - dy
+	x, y := x0, y0
+	for x != x1 || y != y1 {
+		if (x != x0 || y != y0) && (gmgmap.IsWall(structuresLayer.GetTile(x, y)) || isClosedDoor(m, x, y)) {
+			return false
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+	return true
+}
+
+var ansiCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+var dimmed = map[string]string{}
+
+// Explored tiles out of sight are drawn faded, without their colors
+func dimSymbol(symbol string) string {
+	if d, ok := dimmed[symbol]; ok {
+		return d
+	}
+	d := color.New(color.Faint).Sprint(ansiCodes.ReplaceAllString(symbol, ""))
+	dimmed[symbol] = d
+	return d
+}
@@ -74,14 +74,17 @@ func handleGameMenuChoice(g *gocui.Gui, choice int) error {
 	switch choice {
 	case 1: // Save & Continue
 		save.SaveAny("player", gameState.player)
+		saveCurrentLevel()
 		return closeGameMenu(g)
 
 	case 2: // Save & Return to Main Menu
 		save.SaveAny("player", gameState.player)
+		saveCurrentLevel()
 		return ErrReturnToMainMenu
 
 	case 3: // Save & Quit Game
 		save.SaveAny("player", gameState.player)
+		saveCurrentLevel()
 		return gocui.ErrQuit
 
 	case 4: // Cancel
@@ -98,6 +101,9 @@ func setupGameMenuKeybindings(g *gocui.Gui) error {
 		if gameMenuOpen {
 			return closeGameMenu(g)
 		}
+		if fullMapOpen {
+			return toggleFullMap(g, v)
+		}
 		return showGameMenu(g, v)
 	}); err != nil {
 		return err
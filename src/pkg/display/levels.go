@@ -0,0 +1,64 @@
+package display
+
+import (
+	"fmt"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+)
+
+// Each visited level is saved with its entities, so the layout, killed mobs and opened chests survive a reload
+func levelSaveName(level int) string {
+	return fmt.Sprintf("level_%d", -level)
+}
+
+func saveLevel(level int, m *gmgmap.Map) {
+	_ = save.SaveAny(levelSaveName(level), m)
+	saveExplored(level)
+}
+
+func saveCurrentLevel() {
+	if gameState != nil && gameState.gameMap != nil {
+		saveLevel(gameState.currentLevel, gameState.gameMap)
+	}
+}
+
+// Saved layout of the level, nil if it was never visited
+func loadLevel(level int) *gmgmap.Map {
+	m := &gmgmap.Map{}
+	if err := save.LoadAny(levelSaveName(level), m); err != nil || m.Width == 0 {
+		return nil
+	}
+	return m
+}
+
+// Map of the level from memory, the save, or freshly generated
+func levelMap(level int) *gmgmap.Map {
+	if gameState != nil && gameState.maps[level] != nil {
+		return gameState.maps[level]
+	}
+	if m := loadLevel(level); m != nil {
+		return m
+	}
+	if level < 0 && (level%3) == 0 {
+		return generateBossLevel()
+	}
+	return generateMapForLevel(level, structures.GetRNG())
+}
+
+// The dungeon is generated anew after a death
+func deleteSavedLevels() {
+	_ = save.DeleteAll("level_")
+}
+
+func clearPlayerTiles(m *gmgmap.Map) {
+	entities := m.Layer("Entities")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if entities.GetTile(x, y) == gmgmap.Player {
+				entities.SetTile(x, y, gmgmap.Nothing)
+			}
+		}
+	}
+}
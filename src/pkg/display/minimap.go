@@ -0,0 +1,232 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/fatih/color"
+)
+
+const (
+	minimapWidth  = 40
+	minimapHeight = 12
+)
+
+var minimapOpen = false
+var fullMapOpen = false
+
+// Spots the player marked on the map, by level
+type mapMarker struct {
+	X, Y int
+}
+
+var markers = map[int][]mapMarker{}
+
+// Last seen mob tiles, by level
+var seenMobs = map[int]map[[2]int]bool{}
+
+func loadMarkers() {
+	markers = map[int][]mapMarker{}
+	_ = save.LoadAny("markers", &markers)
+}
+
+func saveMarkers() {
+	_ = save.SaveAny("markers", markers)
+}
+
+// Called by the field of view for each tile in sight
+func rememberMob(level, x, y int, isMob bool) {
+	if seenMobs[level] == nil {
+		seenMobs[level] = map[[2]int]bool{}
+	}
+	if isMob {
+		seenMobs[level][[2]int{x, y}] = true
+	} else {
+		delete(seenMobs[level], [2]int{x, y})
+	}
+}
+
+// Puts a marker where the player stands, or removes the one already there
+func toggleMarker(g *gocui.Gui, v *gocui.View) error {
+	if gameState == nil {
+		return nil
+	}
+	level := gameState.currentLevel
+	for i, marker := range markers[level] {
+		if marker.Y == gameState.playerY && marker.X >= gameState.playerX && marker.X <= gameState.playerX+1 {
+			markers[level] = append(markers[level][:i], markers[level][i+1:]...)
+			saveMarkers()
+			return nil
+		}
+	}
+	markers[level] = append(markers[level], mapMarker{X: gameState.playerX, Y: gameState.playerY})
+	saveMarkers()
+	return nil
+}
+
+func toggleMinimap(g *gocui.Gui, v *gocui.View) error {
+	minimapOpen = !minimapOpen
+	if !minimapOpen {
+		_ = g.DeleteView("minimap")
+	}
+	return nil
+}
+
+func toggleFullMap(g *gocui.Gui, v *gocui.View) error {
+	fullMapOpen = !fullMapOpen
+	if !fullMapOpen {
+		_ = g.DeleteView("fullmap")
+		_, _ = g.SetCurrentView("game")
+	}
+	return nil
+}
+
+// Called from gameLayout, the map views are drawn over the game view
+func mapLayout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	if minimapOpen {
+		if v, err := g.SetView("minimap", maxX-minimapWidth-2, 0, maxX-1, minimapHeight+1, 0); err != nil {
+			if !errors.Is(err, gocui.ErrUnknownView) {
+				return err
+			}
+			v.Title = " Minimap "
+			drawMap(v)
+		}
+	}
+	if fullMapOpen {
+		if v, err := g.SetView("fullmap", 0, 0, maxX-1, maxY-4, 0); err != nil {
+			if !errors.Is(err, gocui.ErrUnknownView) {
+				return err
+			}
+			v.Title = " Map - C to close "
+			drawMap(v)
+		}
+	}
+	return nil
+}
+
+// Redraws the open map views, after the player moved
+func refreshMaps(g *gocui.Gui) {
+	for _, name := range []string{"minimap", "fullmap"} {
+		if v, err := g.View(name); err == nil {
+			drawMap(v)
+		}
+	}
+}
+
+// The level shrunk to the view, each char sums up a block of tiles
+func drawMap(v *gocui.View) {
+	if gameState == nil || gameState.gameMap == nil {
+		return
+	}
+	v.Clear()
+	m := gameState.gameMap
+	width, height := v.Size()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	scaleX := (m.Width + width - 1) / width
+	scaleY := (m.Height + height - 1) / height
+
+	for y := 0; y*scaleY < m.Height && y < height; y++ {
+		line := ""
+		for x := 0; x*scaleX < m.Width && x < width; x++ {
+			line += mapCell(m, x*scaleX, y*scaleY, scaleX, scaleY)
+		}
+		fmt.Fprintln(v, line)
+	}
+}
+
+// Most important thing of the block: player, marker, stairs, NPCs, mobs, then the terrain
+func mapCell(m *gmgmap.Map, x0, y0, w, h int) string {
+	level := gameState.currentLevel
+	lit := isLit(level)
+	mask := exploredMask(level, m)
+	structuresLayer := m.Layer("Structures")
+	ground := m.Layer("Ground")
+	entities := m.Layer("Entities")
+	b := biomeFor(level)
+
+	best, symbol := 0, " "
+	pick := func(rank int, s string) {
+		if rank > best {
+			best, symbol = rank, s
+		}
+	}
+	for y := y0; y < y0+h && y < m.Height; y++ {
+		for x := x0; x < x0+w && x < m.Width; x++ {
+			if x >= gameState.playerX && x <= gameState.playerX+1 && y == gameState.playerY {
+				pick(9, color.New(color.FgGreen, color.Bold).Sprint("@"))
+			}
+			for _, marker := range markers[level] {
+				if marker.X == x && marker.Y == y {
+					pick(8, color.New(color.FgMagenta, color.Bold).Sprint("X"))
+				}
+			}
+			if !lit && !mask[y][x] {
+				continue
+			}
+			structure := structuresLayer.GetTile(x, y)
+			switch {
+			case gmgmap.IsStairs(structure):
+				pick(7, gmgmap.GetTileSymbol(structure))
+			case level == 0 && isShopDoor(x, y) != 0:
+				pick(6, shopSymbol(shops[isShopDoor(x, y)-1].Keeper))
+			}
+			switch entity := entities.GetTile(x, y); entity {
+			case gmgmap.Merchant, gmgmap.Blacksmith:
+				pick(6, shopSymbol(entity))
+			case gmgmap.Mob:
+				if lit {
+					pick(5, color.New(color.FgRed).Sprint("m"))
+				}
+			}
+			if !lit && seenMobs[level][[2]int{x, y}] {
+				pick(5, color.New(color.FgRed).Sprint("m"))
+			}
+			if isWalkable(m, x, y) {
+				pick(2, tileSymbol(b, ground.GetTile(x, y)))
+			} else if gmgmap.IsWall(structure) {
+				pick(1, tileSymbol(b, structure))
+			}
+		}
+	}
+	return symbol
+}
+
+func shopSymbol(keeper rune) string {
+	if keeper == gmgmap.Blacksmith {
+		return color.New(color.FgCyan, color.Bold).Sprint("B")
+	}
+	return color.New(color.FgYellow, color.Bold).Sprint("$")
+}
+
+// Level of the shop behind the door tile, 0 if none
+func isShopDoor(x, y int) int {
+	for _, door := range shopDoors {
+		if door.Y == y && (door.X == x || door.X+1 == x) {
+			return door.Level
+		}
+	}
+	return 0
+}
+
+func setupMapKeybindings(g *gocui.Gui) error {
+	for _, binding := range []struct {
+		key     rune
+		handler func(*gocui.Gui, *gocui.View) error
+	}{
+		{'m', toggleMinimap}, {'M', toggleMinimap},
+		{'c', toggleFullMap}, {'C', toggleFullMap},
+		{'n', toggleMarker}, {'N', toggleMarker},
+	} {
+		if err := g.SetKeybinding("", binding.key, gocui.ModNone, binding.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
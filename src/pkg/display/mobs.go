@@ -0,0 +1,235 @@
+package display
+
+import (
+	"fmt"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+	"main/pkg/ui"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Mobs closer than this, in rows, chase the player. Columns count double like for the light radius
+var AggroRadius = 6
+
+// How far a mob strays from the spot it was first seen at
+const patrolRadius = 6
+
+// Mobs patrol between their home and a spot of their room, or wander around it
+type mobState struct {
+	Home    [2]int
+	Target  [2]int
+	Patrol  bool
+	Alerted int // Turns left hunting the player wherever they are
+}
+
+// Mobs of each level by their position, they are given a state when they first move
+var mobStates = map[int]map[[2]int]*mobState{}
+
+// Left tiles of the mobs of the map, mobs are 2 chars wide
+func findMobs(m *gmgmap.Map) [][2]int {
+	entities := m.Layer("Entities")
+	var mobs [][2]int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width-1; x++ {
+			if entities.GetTile(x, y) == gmgmap.Mob && entities.GetTile(x+1, y) == gmgmap.Mob {
+				mobs = append(mobs, [2]int{x, y})
+				x++
+			}
+		}
+	}
+	return mobs
+}
+
+// Turn of the mobs after each step of the player, the first one reaching the player starts a fight
+func moveMobs(g *gocui.Gui) (bool, error) {
+	level := gameState.currentLevel
+	if level >= 0 {
+		return false, nil
+	}
+	m := gameState.gameMap
+	rng := structures.GetRNG()
+	if mobStates[level] == nil {
+		mobStates[level] = map[[2]int]*mobState{}
+	}
+	states := mobStates[level]
+	player := [2]int{gameState.playerX, gameState.playerY}
+
+	for _, mob := range findMobs(m) {
+		state := mobStateFor(level, mob)
+		delete(states, mob)
+
+		next := mob
+		if state.Alerted > 0 {
+			state.Alerted--
+		}
+		if inAggroRange(mob, player) || state.Alerted > 0 {
+			if touchesPlayer(mob) {
+				return true, ambush(g, mob)
+			}
+			if path, found := gmgmap.FindPath(m.Width, m.Height, mobPassable(m, mob, player), mob[0], mob[1], player[0], player[1]); found && len(path) > 2 {
+				next = path[1]
+			}
+		} else if state.Patrol {
+			if mob == state.Target {
+				state.Target = patrolSpot(m, state.Home)
+			}
+			if path, found := gmgmap.FindPath(m.Width, m.Height, mobPassable(m, mob, player), mob[0], mob[1], state.Target[0], state.Target[1]); found && len(path) > 1 {
+				next = path[1]
+			} else {
+				state.Target = mob
+			}
+		} else if rng.Intn(2) == 0 {
+			d := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}[rng.Intn(4)]
+			spot := [2]int{mob[0] + d[0], mob[1] + d[1]}
+			if mobPassable(m, mob, player)(spot[0], spot[1]) && inPatrolRange(spot, state.Home) {
+				next = spot
+			}
+		}
+
+		if next != mob {
+			moveMob(m, mob, next)
+		}
+		states[next] = state
+
+		if touchesPlayer(next) && (inAggroRange(next, player) || state.Alerted > 0) {
+			return true, ambush(g, next)
+		}
+	}
+	return false, nil
+}
+
+func mobStateFor(level int, mob [2]int) *mobState {
+	if mobStates[level] == nil {
+		mobStates[level] = map[[2]int]*mobState{}
+	}
+	state := mobStates[level][mob]
+	if state == nil {
+		state = &mobState{Home: mob, Target: mob, Patrol: structures.GetRNG().Intn(2) == 0}
+		mobStates[level][mob] = state
+	}
+	return state
+}
+
+// Mobs within radius rows of the player start hunting them
+func alertMobs(radius int) {
+	for _, mob := range findMobs(gameState.gameMap) {
+		dx, dy := mob[0]-gameState.playerX, mob[1]-gameState.playerY
+		if dx*dx+4*dy*dy <= 4*radius*radius {
+			mobStateFor(gameState.currentLevel, mob).Alerted = alarmTurns
+		}
+	}
+}
+
+// Actions other than walking take a turn too
+func endTurn(g *gocui.Gui) error {
+	if died, err := tickEffects(g); died {
+		return err
+	}
+	if fought, err := moveMobs(g); fought {
+		return err
+	}
+	g.Update(func(g *gocui.Gui) error {
+		if gameView, _ := g.View("game"); gameView != nil {
+			updateGameView(gameView)
+		}
+		return nil
+	})
+	return nil
+}
+
+// Burn, bleed and poison keep hurting on the map, one turn per step
+func tickEffects(g *gocui.Gui) (bool, error) {
+	player := gameState.player
+	messages := structures.TickAilments(&player.Entity)
+	if len(messages) == 0 {
+		return false, nil
+	}
+	save.SaveAny("player", player)
+	if player.Entity.Alive {
+		return false, nil
+	}
+	g.Close()
+	ui.ClearScreen()
+	for _, msg := range messages {
+		fmt.Println(msg)
+	}
+	fmt.Println("Press Enter to continue...")
+	fmt.Scanln()
+	return true, handlePlayerDeath()
+}
+
+func inAggroRange(mob, player [2]int) bool {
+	dx, dy := mob[0]-player[0], mob[1]-player[1]
+	return dx*dx+4*dy*dy <= 4*AggroRadius*AggroRadius
+}
+
+func inPatrolRange(spot, home [2]int) bool {
+	dx, dy := spot[0]-home[0], spot[1]-home[1]
+	return dx*dx+4*dy*dy <= 4*patrolRadius*patrolRadius
+}
+
+// Mob right next to the player, on its row or on the row above or below
+func touchesPlayer(mob [2]int) bool {
+	dx, dy := mob[0]-gameState.playerX, mob[1]-gameState.playerY
+	if dy == 0 {
+		return dx == 2 || dx == -2
+	}
+	return (dy == 1 || dy == -1) && dx >= -1 && dx <= 1
+}
+
+// Spots the mob at from can stand on, the player's spot is kept so it can be the end of a path
+func mobPassable(m *gmgmap.Map, from, player [2]int) func(x, y int) bool {
+	entities := m.Layer("Entities")
+	return func(x, y int) bool {
+		if x == player[0] && y == player[1] {
+			return true
+		}
+		if r := 2 * (AggroRadius + patrolRadius); (x-from[0])*(x-from[0])+4*(y-from[1])*(y-from[1]) > 4*r*r { // Keeps the search around the mob
+			return false
+		}
+		for i := 0; i < 2; i++ {
+			if !isWalkable(m, x+i, y) || isClosedDoor(m, x+i, y) {
+				return false
+			}
+			tile := entities.GetTile(x+i, y)
+			own := y == from[1] && (x+i == from[0] || x+i == from[0]+1)
+			if tile != gmgmap.Nothing && !own {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Random spot of the mob's room it can walk to, its home if none is found
+func patrolSpot(m *gmgmap.Map, home [2]int) [2]int {
+	rng := structures.GetRNG()
+	near := reachable(m, home[:])
+	for try := 0; try < 10; try++ {
+		spot := [2]int{home[0] + rng.Intn(4*patrolRadius+1) - 2*patrolRadius, home[1] + rng.Intn(2*patrolRadius+1) - patrolRadius}
+		if near[spot] && inPatrolRange(spot, home) {
+			return spot
+		}
+	}
+	return home
+}
+
+func moveMob(m *gmgmap.Map, from, to [2]int) {
+	entities := m.Layer("Entities")
+	entities.SetTile(from[0], from[1], gmgmap.Nothing)
+	entities.SetTile(from[0]+1, from[1], gmgmap.Nothing)
+	entities.SetTile(to[0], to[1], gmgmap.Mob)
+	entities.SetTile(to[0]+1, to[1], gmgmap.Mob)
+}
+
+// The mob caught up with the player, it is taken off the map for the fight
+func ambush(g *gocui.Gui, mob [2]int) error {
+	entities := gameState.gameMap.Layer("Entities")
+	entities.SetTile(mob[0], mob[1], gmgmap.Nothing)
+	entities.SetTile(mob[0]+1, mob[1], gmgmap.Nothing)
+	delete(mobStates[gameState.currentLevel], mob)
+	return fightMob(g, gameState.playerX, gameState.playerY, true)
+}
@@ -44,5 +44,9 @@ func respawnPlayer(player *structures.Player) {
 		Type:    "Boots",
 		Defense: 0,
 	}
+	player.Entity.Ring1 = structures.NoArmor("Ring")
+	player.Entity.Ring2 = structures.NoArmor("Ring")
+	player.Entity.Amulet = structures.NoArmor("Amulet")
+	player.Entity.Shield = structures.NoArmor("Shield")
 
 }
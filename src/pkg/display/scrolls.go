@@ -0,0 +1,215 @@
+package display
+
+import (
+	"fmt"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+	"main/pkg/ui"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Where the town portal was opened, walking into the portal in town goes back there
+type townPortal struct {
+	Open  bool
+	Level int
+	X, Y  int
+}
+
+var portal townPortal
+
+// Levels read with a Magic Mapping scroll
+var mappedLevels = map[int]bool{}
+
+func loadScrollState() {
+	portal = townPortal{}
+	_ = save.LoadAny("portal", &portal)
+	mappedLevels = map[int]bool{}
+	_ = save.LoadAny("mapped", &mappedLevels)
+}
+
+func saveScrollState() {
+	_ = save.SaveAny("portal", portal)
+	_ = save.SaveAny("mapped", mappedLevels)
+}
+
+// Called from the inventory when a scroll is read
+func readScroll(g *gocui.Gui, player *structures.Player, scroll structures.Scroll) (string, bool) {
+	if gameState == nil {
+		return "Nothing happens.", false
+	}
+	switch scroll.Type {
+	case "TownPortal":
+		return readTownPortal(g)
+	case "MagicMapping":
+		return readMagicMapping()
+	case "Teleport":
+		return readTeleport(g)
+	case "Identify":
+		if name, ok := player.IdentifyNext(); ok {
+			return fmt.Sprintf("The scroll reveals a %s.", name), true
+		}
+		if player.LiftCurse() {
+			return "Nothing left to identify, the scroll burns a curse away instead.", true
+		}
+		return "You have nothing to identify.", false
+	}
+	return "Nothing happens.", false
+}
+
+func readTownPortal(g *gocui.Gui) (string, bool) {
+	if gameState.currentLevel >= 0 {
+		return "You are already in town.", false
+	}
+	portal = townPortal{Open: true, Level: gameState.currentLevel, X: gameState.playerX, Y: gameState.playerY}
+	saveScrollState()
+
+	ui.CloseInventory(g)
+	if err := enterLevel(g, 0, gmgmap.StairsDown); err != nil {
+		return "The portal fizzles out.", false
+	}
+	placePortal(gameState.gameMap, gameState.playerX, gameState.playerY)
+	return fmt.Sprintf("A portal carries you back to town. Walk into it to return to level %d.", portal.Level), true
+}
+
+// Puts the portal next to the given spot, or anywhere free in town
+func placePortal(m *gmgmap.Map, x, y int) {
+	if spot, ok := nearbySpot(m, x, y); ok {
+		entities := m.Layer("Entities")
+		entities.SetTile(spot[0], spot[1], gmgmap.Portal)
+		entities.SetTile(spot[0]+1, spot[1], gmgmap.Portal) // Portals are 2 chars wide
+	}
+}
+
+// Free spot next to x,y for a 2 chars wide entity, or the first free one of the level
+func nearbySpot(m *gmgmap.Map, x, y int) ([]int, bool) {
+	entities := m.Layer("Entities")
+	for _, spot := range [][]int{{x + 2, y}, {x - 2, y}, {x, y + 1}, {x, y - 1}, {x + 2, y + 1}, {x - 2, y + 1}, {x + 2, y - 1}, {x - 2, y - 1}} {
+		if canMoveTo(m, spot[0], spot[1]) && entities.GetTile(spot[0], spot[1]) == gmgmap.Nothing && entities.GetTile(spot[0]+1, spot[1]) == gmgmap.Nothing {
+			return spot, true
+		}
+	}
+	if spots := freeSpots(m); len(spots) > 0 {
+		return spots[0], true
+	}
+	return nil, false
+}
+
+// Walking into the portal takes the player back where the scroll was read, and closes it
+func usePortal(g *gocui.Gui) error {
+	entities := gameState.gameMap.Layer("Entities")
+	for y := 0; y < gameState.gameMap.Height; y++ {
+		for x := 0; x < gameState.gameMap.Width; x++ {
+			if entities.GetTile(x, y) == gmgmap.Portal {
+				entities.SetTile(x, y, gmgmap.Nothing)
+			}
+		}
+	}
+	if !portal.Open {
+		return nil
+	}
+	target := portal
+	portal = townPortal{}
+	saveScrollState()
+
+	if err := enterLevel(g, target.Level, gmgmap.StairsUp); err != nil {
+		return err
+	}
+	relocatePlayer(target.X, target.Y)
+	return nil
+}
+
+func readMagicMapping() (string, bool) {
+	if gameState.currentLevel >= 0 {
+		return "The town holds no secrets.", false
+	}
+	mappedLevels[gameState.currentLevel] = true
+	saveScrollState()
+	for _, row := range exploredMask(gameState.currentLevel, gameState.gameMap) {
+		for x := range row {
+			row[x] = true
+		}
+	}
+	saveExplored(gameState.currentLevel)
+
+	msg := "The layout of the level burns into your mind."
+	m := gameState.gameMap
+	structuresLayer := m.Layer("Structures")
+	found := false
+	for y := 0; y < m.Height && !found; y++ {
+		for x := 0; x < m.Width && !found; x++ {
+			if structuresLayer.GetTile(x, y) == gmgmap.StairsDown {
+				msg += fmt.Sprintf(" Stairs down lie to the %s.", direction(x-gameState.playerX, y-gameState.playerY))
+				found = true
+			}
+		}
+	}
+	closed := 0
+	for _, c := range chests[gameState.currentLevel] {
+		if !c.Opened {
+			closed++
+		}
+	}
+	return msg + fmt.Sprintf(" %d chest(s) left.", closed), true
+}
+
+func direction(dx, dy int) string {
+	dir := ""
+	if dy < 0 {
+		dir = "north"
+	} else if dy > 0 {
+		dir = "south"
+	}
+	if dx < 0 {
+		dir += "west"
+	} else if dx > 0 {
+		dir += "east"
+	}
+	if dir == "" {
+		return "here"
+	}
+	return dir
+}
+
+// Random free tile of the level, found like the fallback spawn of the stairs
+func readTeleport(g *gocui.Gui) (string, bool) {
+	if !teleportPlayer() {
+		return "The scroll crumbles, there is nowhere to go.", false
+	}
+	g.Update(func(g *gocui.Gui) error {
+		if gameView, _ := g.View("game"); gameView != nil {
+			updateGameView(gameView)
+		}
+		return nil
+	})
+	return "The world blurs and you find yourself elsewhere.", true
+}
+
+// Moves the player to a random free spot of the level
+func teleportPlayer() bool {
+	m := gameState.gameMap
+	structuresLayer := m.Layer("Structures")
+	vault := vaultArea(m)
+	var spots [][]int
+	for _, spot := range freeSpots(m) {
+		if vault[[2]int{spot[0], spot[1]}] {
+			continue
+		}
+		if structuresLayer.GetTile(spot[0], spot[1]) == gmgmap.Nothing && structuresLayer.GetTile(spot[0]+1, spot[1]) == gmgmap.Nothing {
+			spots = append(spots, spot)
+		}
+	}
+	if len(spots) == 0 {
+		return false
+	}
+	spot := spots[structures.GetRNG().Intn(len(spots))]
+	structures.RefreshSeedState()
+
+	movePlayer(m, gameState.playerX, gameState.playerY, spot[0], spot[1])
+	gameState.playerX = spot[0]
+	gameState.playerY = spot[1]
+	_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
+	return true
+}
@@ -0,0 +1,217 @@
+package display
+
+import (
+	"math/rand"
+	"sort"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	shopWidth  = 24
+	shopHeight = 12
+)
+
+// Shop interiors are stored as levels 1, 2... behind a door in town
+type shop struct {
+	Name   string
+	Keeper rune
+}
+
+var shops = []shop{
+	{Name: "Merchant's shop", Keeper: gmgmap.Merchant},
+	{Name: "Blacksmith's forge", Keeper: gmgmap.Blacksmith},
+}
+
+// Door of a shop building in town
+type shopDoor struct {
+	Level int
+	X, Y  int
+}
+
+var shopDoors []shopDoor
+
+func loadShopDoors() {
+	shopDoors = nil
+	_ = save.LoadAny("shop_doors", &shopDoors)
+}
+
+func saveShopDoors() {
+	_ = save.SaveAny("shop_doors", shopDoors)
+}
+
+// Village with its houses and villagers, the dungeon entrance and the shop doors
+func generateTown(rng *rand.Rand) *gmgmap.Map {
+	m := gmgmap.NewVillage(rng, func(_ *gmgmap.Map) {}, mapWidth, mapHeight, 2)
+	ground := m.Layer("Ground")
+	structuresLayer := m.Layer("Structures")
+	entities := m.Layer("Entities")
+
+	// Building doors are 1 tile wide, the player is 2
+	var doors [][]int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width-1; x++ {
+			if structuresLayer.GetTile(x, y) != gmgmap.Door {
+				continue
+			}
+			structuresLayer.SetTile(x+1, y, gmgmap.Door)
+			ground.SetTile(x+1, y, ground.GetTile(x, y))
+			if isWalkable(m, x, y+1) {
+				carve(m, x+1, y+1, x, y+1)
+			}
+			doors = append(doors, []int{x, y})
+			x++
+		}
+	}
+
+	// Village NPCs stand where placeNPCs put them
+	characters := m.Layer("Characters")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if characters.GetTile(x, y) == gmgmap.Nothing {
+				continue
+			}
+			for _, vx := range []int{x, x - 1} {
+				if isWalkable(m, vx, y) && isWalkable(m, vx+1, y) && entities.GetTile(vx, y) == gmgmap.Nothing && entities.GetTile(vx+1, y) == gmgmap.Nothing {
+					entities.SetTile(vx, y, gmgmap.Villager)
+					entities.SetTile(vx+1, y, gmgmap.Villager) // Villagers are 2 chars wide
+					break
+				}
+			}
+		}
+	}
+	m.RemoveLayer("Characters")
+
+	widenPassages(m)
+
+	// The dungeon entrance is on the paths, as close to the middle of the village as possible
+	center := []int{m.Width / 2, m.Height / 2}
+	var entrance []int
+	for _, spot := range freeSpots(m) {
+		outdoor := true
+		for i := 0; i < 2; i++ {
+			groundTile := ground.GetTile(spot[0]+i, spot[1])
+			if structuresLayer.GetTile(spot[0]+i, spot[1]) != gmgmap.Nothing || groundTile == gmgmap.Room || groundTile == gmgmap.Room2 {
+				outdoor = false
+			}
+		}
+		if outdoor && (entrance == nil || distance(spot, center) < distance(entrance, center)) {
+			entrance = spot
+		}
+	}
+	if entrance == nil {
+		placeStairs(m, rng, false)
+	} else {
+		structuresLayer.SetTile(entrance[0], entrance[1], gmgmap.StairsDown)
+	}
+
+	// The shops are the buildings closest to the entrance
+	if entrance != nil {
+		sort.SliceStable(doors, func(i, j int) bool {
+			return distance(doors[i], entrance) < distance(doors[j], entrance)
+		})
+	}
+	shopDoors = nil
+	for i := 0; i < len(shops) && i < len(doors); i++ {
+		shopDoors = append(shopDoors, shopDoor{Level: i + 1, X: doors[i][0], Y: doors[i][1]})
+	}
+	saveShopDoors()
+
+	return m
+}
+
+// Interior of a shop, its keeper waits at the counter in front of the door
+func generateShop(level int, rng *rand.Rand) *gmgmap.Map {
+	m := gmgmap.NewShop(rng, func(_ *gmgmap.Map) {}, shopWidth, shopHeight)
+	m.RemoveLayer("Characters") // Shopkeeper, assistants and patrons
+
+	keeperX, keeperY := m.Width/2, 3
+	furniture := m.Layer("Furniture")
+	stock := m.Layer("Inventory")
+	entities := m.Layer("Entities")
+	for i := 0; i < 2; i++ {
+		// Aisle from the door to the counter
+		for y := keeperY; y < m.Height-3; y++ {
+			furniture.SetTile(keeperX+i, y, gmgmap.Nothing)
+			stock.SetTile(keeperX+i, y, gmgmap.Nothing)
+		}
+		entities.SetTile(keeperX+i, keeperY, shops[level-1].Keeper) // Keepers are 2 chars wide
+	}
+	return m
+}
+
+// Spot next to the dungeon entrance, where the player starts in town
+func townStart(m *gmgmap.Map) ([]int, bool) {
+	structuresLayer := m.Layer("Structures")
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if structuresLayer.GetTile(x, y) == gmgmap.StairsDown {
+				return nearbySpot(m, x, y)
+			}
+		}
+	}
+	return nearbySpot(m, 0, 0)
+}
+
+// Walking through a shop door in town goes inside, the shop door leads back out
+func useShopDoor(g *gocui.Gui) (bool, error) {
+	x, y := gameState.playerX, gameState.playerY
+	if gameState.currentLevel == 0 {
+		for _, door := range shopDoors {
+			if door.Y == y && door.X >= x-1 && door.X <= x+1 {
+				return true, enterShop(g, door.Level)
+			}
+		}
+		return false, nil
+	}
+	if gameState.currentLevel > 0 {
+		structuresLayer := gameState.gameMap.Layer("Structures")
+		if structuresLayer.GetTile(x, y) == gmgmap.Door || structuresLayer.GetTile(x+1, y) == gmgmap.Door {
+			return true, leaveShop(g)
+		}
+	}
+	return false, nil
+}
+
+func enterShop(g *gocui.Gui, level int) error {
+	if err := enterLevel(g, level, gmgmap.Door); err != nil {
+		return err
+	}
+	relocatePlayer(gameState.gameMap.Width/2, gameState.gameMap.Height-4)
+	return nil
+}
+
+func leaveShop(g *gocui.Gui) error {
+	level := gameState.currentLevel
+	if err := enterLevel(g, 0, gmgmap.StairsDown); err != nil {
+		return err
+	}
+	for _, door := range shopDoors {
+		if door.Level == level {
+			relocatePlayer(door.X, door.Y+1)
+		}
+	}
+	return nil
+}
+
+// Moves the player to x,y once enterLevel placed them, if the spot is free
+func relocatePlayer(x, y int) {
+	m := gameState.gameMap
+	entities := m.Layer("Entities")
+	for i := 0; i < 2; i++ {
+		tile := entities.GetTile(x+i, y)
+		if tile != gmgmap.Nothing && tile != gmgmap.Player {
+			return
+		}
+	}
+	if !canMoveTo(m, x, y) {
+		return
+	}
+	movePlayer(m, gameState.playerX, gameState.playerY, x, y)
+	gameState.playerX = x
+	gameState.playerY = y
+	_ = save.SaveWorldState(save.WorldState{CurrentLevel: gameState.currentLevel, PlayerX: gameState.playerX, PlayerY: gameState.playerY})
+}
@@ -0,0 +1,257 @@
+package display
+
+import (
+	"fmt"
+	"math/rand"
+
+	"main/pkg/gmgmap"
+	"main/pkg/save"
+	"main/pkg/structures"
+	"main/pkg/ui"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	maxTraps      = 12
+	trapStairsGap = 4  // No trap this close to the stairs
+	alarmRadius   = 15 // In rows, like the light radius
+	alarmTurns    = 20 // How long alerted mobs hunt the player
+	searchRadius  = 4
+	noticeRadius  = 2 // Perception only works on traps right next to the player
+)
+
+var trapNames = map[rune]string{
+	gmgmap.TrapSpikes:   "Spike trap",
+	gmgmap.TrapGas:      "Gas trap",
+	gmgmap.TrapAlarm:    "Alarm trap",
+	gmgmap.TrapTeleport: "Teleport trap",
+}
+
+// Rolled in this order, spikes are the most common
+var trapTable = []rune{gmgmap.TrapSpikes, gmgmap.TrapSpikes, gmgmap.TrapGas, gmgmap.TrapAlarm, gmgmap.TrapTeleport}
+
+// Traps the player found, by level. The others are drawn as the floor under them
+var detectedTraps = map[int]map[[2]int]bool{}
+
+func loadTraps() {
+	detectedTraps = map[int]map[[2]int]bool{}
+	saved := map[int][][2]int{}
+	_ = save.LoadAny("traps", &saved)
+	for level, spots := range saved {
+		for _, spot := range spots {
+			revealTrap(level, spot[0], spot[1])
+		}
+	}
+}
+
+func saveTraps() {
+	saved := map[int][][2]int{}
+	for level, spots := range detectedTraps {
+		for spot := range spots {
+			saved[level] = append(saved[level], spot)
+		}
+	}
+	_ = save.SaveAny("traps", saved)
+}
+
+func trapDetected(level, x, y int) bool {
+	return detectedTraps[level][[2]int{x, y}]
+}
+
+func revealTrap(level, x, y int) {
+	if detectedTraps[level] == nil {
+		detectedTraps[level] = map[[2]int]bool{}
+	}
+	detectedTraps[level][[2]int{x, y}] = true
+}
+
+// Hidden traps on the floor, more of them deeper
+func placeTraps(m *gmgmap.Map, level int, rng *rand.Rand) {
+	structuresLayer := m.Layer("Structures")
+	var stairs, spots [][]int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if gmgmap.IsStairs(structuresLayer.GetTile(x, y)) {
+				stairs = append(stairs, []int{x, y})
+			}
+		}
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !isWalkable(m, x, y) || structuresLayer.GetTile(x, y) != gmgmap.Nothing {
+				continue
+			}
+			nearStairs := false
+			for _, s := range stairs {
+				if distance(s, []int{x, y}) < trapStairsGap {
+					nearStairs = true
+				}
+			}
+			if !nearStairs {
+				spots = append(spots, []int{x, y})
+			}
+		}
+	}
+	count := 3 - level/2
+	if count > maxTraps {
+		count = maxTraps
+	}
+	for i := 0; i < count && len(spots) > 0; i++ {
+		j := rng.Intn(len(spots))
+		structuresLayer.SetTile(spots[j][0], spots[j][1], trapTable[rng.Intn(len(trapTable))])
+		spots = append(spots[:j], spots[j+1:]...)
+	}
+}
+
+// Traps around the player within radius rows, columns count double
+func trapsAround(radius int) [][2]int {
+	m := gameState.gameMap
+	structuresLayer := m.Layer("Structures")
+	var found [][2]int
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -2 * radius; dx <= 2*radius+1; dx++ {
+			x, y := gameState.playerX+dx, gameState.playerY+dy
+			if dx*dx+4*dy*dy <= 4*radius*radius && gmgmap.IsTrap(structuresLayer.GetTile(x, y)) {
+				found = append(found, [2]int{x, y})
+			}
+		}
+	}
+	return found
+}
+
+// Perceptive races spot the traps next to them as they walk by
+func noticeTraps() {
+	chance := gameState.player.Perception()
+	if chance <= 0 {
+		return
+	}
+	rng := structures.GetRNG()
+	changed := false
+	for _, t := range trapsAround(noticeRadius) {
+		if !trapDetected(gameState.currentLevel, t[0], t[1]) && rng.Intn(100) < chance {
+			revealTrap(gameState.currentLevel, t[0], t[1])
+			changed = true
+		}
+	}
+	if changed {
+		saveTraps()
+	}
+}
+
+// Looks for traps around the player, it takes a turn
+func searchTraps(g *gocui.Gui, v *gocui.View) error {
+	if gameState == nil || gameState.currentLevel >= 0 || fullMapOpen {
+		return nil
+	}
+	rng := structures.GetRNG()
+	chance := gameState.player.SearchChance()
+	for _, t := range trapsAround(searchRadius) {
+		if rng.Intn(100) < chance {
+			revealTrap(gameState.currentLevel, t[0], t[1])
+		}
+	}
+	saveTraps()
+	return endTurn(g)
+}
+
+// Sets off the traps under the tiles the player just stepped on
+func stepOnTrap(g *gocui.Gui, oldX, oldY int) (bool, error) {
+	structuresLayer := gameState.gameMap.Layer("Structures")
+	for i := 0; i < 2; i++ {
+		x, y := gameState.playerX+i, gameState.playerY
+		if y == oldY && (x == oldX || x == oldX+1) {
+			continue
+		}
+		if tile := structuresLayer.GetTile(x, y); gmgmap.IsTrap(tile) {
+			g.Close()
+			ui.ClearScreen()
+			triggerTrap(tile, x, y)
+			return true, afterTrap()
+		}
+	}
+	return false, nil
+}
+
+// Walking into a known trap offers to disarm it instead
+func bumpTrap(g *gocui.Gui, x, y int) (bool, error) {
+	structuresLayer := gameState.gameMap.Layer("Structures")
+	for i := 0; i < 2; i++ {
+		tx := x + i
+		if y == gameState.playerY && (tx == gameState.playerX || tx == gameState.playerX+1) {
+			continue
+		}
+		tile := structuresLayer.GetTile(tx, y)
+		if !gmgmap.IsTrap(tile) || !trapDetected(gameState.currentLevel, tx, y) {
+			continue
+		}
+		player := gameState.player
+		g.Close()
+		ui.ClearScreen()
+
+		name := trapNames[tile]
+		fmt.Printf("There is a %s in your way.\n", name)
+		if askYesNo(fmt.Sprintf("Try to disarm it? (%d%% chance)", player.DisarmChance())) {
+			if parts, ok := player.DisarmTrap(name); ok {
+				structuresLayer.SetTile(tx, y, gmgmap.Nothing)
+				delete(detectedTraps[gameState.currentLevel], [2]int{tx, y})
+				saveTraps()
+				fmt.Println("You disarm the trap and take it apart:")
+				received, leftBehind := player.AddItems(parts)
+				for _, entry := range received {
+					fmt.Printf("  + %s\n", entry.GetItem().Name)
+				}
+				if len(leftBehind) > 0 {
+					fmt.Println("Too heavy to carry, dropped on the floor.")
+					player.DropOnFloor(leftBehind)
+				}
+			} else {
+				fmt.Println("Your hand slips and the trap goes off!")
+				triggerTrap(tile, tx, y)
+			}
+		}
+		return true, afterTrap()
+	}
+	return false, nil
+}
+
+func triggerTrap(tile rune, x, y int) {
+	player := gameState.player
+	revealTrap(gameState.currentLevel, x, y)
+	saveTraps()
+	depth := -gameState.currentLevel
+
+	switch tile {
+	case gmgmap.TrapSpikes:
+		damage := player.Entity.TakeDamage(structures.TrapDamage(depth))
+		fmt.Printf("Spikes shoot out of the floor! You take %d damage.\n", damage)
+	case gmgmap.TrapGas:
+		damage := player.Entity.TakeDamage(structures.TrapDamage(depth) / 2)
+		player.Entity.AddEffect(structures.TrapPoison())
+		fmt.Printf("A cloud of gas bursts out of the floor! You take %d damage and are Poisoned.\n", damage)
+	case gmgmap.TrapAlarm:
+		alertMobs(alarmRadius)
+		fmt.Println("A shrill alarm rings out, every monster around is coming for you!")
+	case gmgmap.TrapTeleport:
+		if teleportPlayer() {
+			fmt.Println("The floor glows and you find yourself elsewhere.")
+		} else {
+			fmt.Println("The floor glows for a moment, then fades.")
+		}
+	}
+}
+
+// Back to the game once the trap message was read
+func afterTrap() error {
+	fmt.Println("Press Enter to continue...")
+	fmt.Scanln()
+	if !gameState.player.Entity.Alive {
+		return handlePlayerDeath()
+	}
+	dropLoot(gameState.gameMap, gameState.currentLevel, gameState.playerX, gameState.playerY, gameState.player.TakeDropped())
+	saveCurrentLevel()
+	save.SaveAny("player", gameState.player)
+
+	ui.ClearScreen()
+	return restartGameLoop()
+}
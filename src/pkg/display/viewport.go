@@ -0,0 +1,19 @@
+package display
+
+import "main/pkg/gmgmap"
+
+// Top left tile of the camera, centered on the player and kept inside the map
+func cameraOrigin(m *gmgmap.Map, width, height int) (int, int) {
+	return cameraAxis(gameState.playerX+1, width, m.Width), cameraAxis(gameState.playerY, height, m.Height)
+}
+
+func cameraAxis(center, viewSize, mapSize int) int {
+	origin := center - viewSize/2
+	if origin > mapSize-viewSize {
+		origin = mapSize - viewSize
+	}
+	if origin < 0 {
+		origin = 0
+	}
+	return origin
+}
@@ -29,9 +29,22 @@ func readLine(reader *bufio.Reader) string {
 	return strings.TrimSpace(input)
 }
 
-func getStartingPlayer(player *structures.Player, enemy *structures.Enemy) bool {
-	playerInitiative := player.Entity.Initiative
-	enemyInitiative := enemy.Entity.Initiative
+// Initiative bonus of an enemy that caught the player on the map
+const AmbushInitiative = 10
+
+func getStartingPlayer(player *structures.Player, enemy *structures.Enemy, enemyBonus int) bool {
+	if player.Weapon.StrikesFirst() != enemy.Weapon.StrikesFirst() { // Spears always open the fight
+		return player.Weapon.StrikesFirst()
+	}
+
+	playerInitiative := player.TotalInitiative()
+	enemyInitiative := enemy.Entity.Initiative + enemyBonus
+	if haste, ok := player.Entity.EffectModifier("Haste"); ok {
+		playerInitiative += int(haste)
+	}
+	if haste, ok := enemy.Entity.EffectModifier("Haste"); ok {
+		enemyInitiative += int(haste)
+	}
 
 	playerRoll := structures.GetRNG().Intn(10) + 1
 	enemyRoll := structures.GetRNG().Intn(10) + 1
@@ -44,9 +57,19 @@ func getStartingPlayer(player *structures.Player, enemy *structures.Enemy) bool
 
 func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 	fmt.Printf("%v has come across the malicious %v!\n", character.Entity.Name, enemy.Entity.Name)
+	return fight(character, enemy, 0)
+}
+
+// Fight started by the enemy, it gets the initiative advantage
+func StartAmbush(character *structures.Player, enemy *structures.Enemy) bool {
+	fmt.Printf("The malicious %v caught up with %v!\n", enemy.Entity.Name, character.Entity.Name)
+	return fight(character, enemy, AmbushInitiative)
+}
+
+func fight(character *structures.Player, enemy *structures.Enemy, enemyBonus int) bool {
 	fmt.Println("Determining who will start...")
 
-	playerTurn := getStartingPlayer(character, enemy)
+	playerTurn := getStartingPlayer(character, enemy, enemyBonus)
 	if playerTurn {
 		fmt.Printf("%v will start the fight!\n\n", character.Entity.Name)
 	} else {
@@ -55,7 +78,7 @@ func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 
 	reader := bufio.NewReader(os.Stdin)
 	roundNumber := 0
-	speed := 30 * time.Millisecond
+	speed := character.QTESpeed(30 * time.Millisecond) // Faster when carrying too much
 
 	time.Sleep(4 * time.Second)
 	ui.ClearScreen()
@@ -64,13 +87,19 @@ func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 		roundNumber++
 
 		if playerTurn {
-			maxMana := 100 + character.Race.BonusMana
+			maxMana := character.MaxMana()
 			if character.Mana < maxMana {
 				character.Mana += 10
 				if character.Mana > maxMana {
 					character.Mana = maxMana
 				}
 			}
+			if regen, ok := character.Entity.SetEffectValue("Regen"); ok && character.HP < character.MaxHP {
+				character.HP += regen
+				if character.HP > character.MaxHP {
+					character.HP = character.MaxHP
+				}
+			}
 		}
 
 		RenderFight(character, enemy, playerTurn, roundNumber)
@@ -92,6 +121,9 @@ func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 					rawDamage, actualDamage := character.InflictDamage("Melee", &enemy.Entity, structures.AllSpells["None"], 1.0)
 					fmt.Printf("[%s] used their weapon dealing %d damage (%d before defense) to [%s]!\n",
 						character.Entity.Name, actualDamage, rawDamage, enemy.Entity.Name)
+					if character.Weapon.Wear(1) {
+						fmt.Printf("Your %s broke! Get it repaired at the blacksmith.\n", character.Weapon.Name)
+					}
 					chosen = true
 					time.Sleep(2 * time.Second)
 					ui.ClearScreen()
@@ -180,9 +212,24 @@ func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 				}
 			}
 
+			if dodge, ok := character.Entity.SetEffectValue("Dodge"); ok && structures.GetRNG().Intn(100) < dodge {
+				fmt.Printf("[%s] slipped through the void and dodged the attack of [%s]!\n", character.Entity.Name, enemy.Entity.Name)
+				time.Sleep(2 * time.Second)
+				playerTurn = !playerTurn
+				continue
+			}
+
 			fmt.Println("\n!!! Incoming attack !!!")
 			fmt.Println("Quick Time Event: Perfect timing blocks 100% damage, good timing blocks 40%!")
 			damageMultiplier := QuickTimeEvent(speed, 20)
+			if parried := character.Weapon.ParryMultiplier(damageMultiplier); parried != damageMultiplier {
+				damageMultiplier = parried
+				fmt.Printf("You parried with your %s!\n", character.Weapon.Name)
+			}
+			if damageMultiplier > 0 && structures.GetRNG().Intn(100) < character.Entity.BlockChance() {
+				damageMultiplier *= 0.5
+				fmt.Printf("Your %s absorbed half of the blow!\n", character.Entity.Shield.Name)
+			}
 
 			baseDamage := enemy.EnemyRace.BonusDamage + enemy.Weapon.Damage
 			if action == "Spell" {
@@ -193,6 +240,16 @@ func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 			blockedDamage := int(float64(baseDamage) * (1.0 - damageMultiplier))
 
 			rawDamage, actualDamage := enemy.InflictDamage(action, &character.Entity, chosenSpell, damageMultiplier)
+			if lightning, ok := character.Entity.SetEffectValue("LightningProc"); ok && damageMultiplier == 0.0 {
+				dealt := enemy.Entity.TakeDamage(lightning)
+				structures.ApplySpellEffect(structures.Spell{Element: "Lightning"}, &enemy.Entity)
+				fmt.Printf("Your StormBringer armor strikes back with lightning for %d damage!\n", dealt)
+			}
+			if actualDamage > 0 {
+				for _, piece := range character.Entity.WearArmor(1) {
+					fmt.Printf("Your %s broke! Get it repaired at the blacksmith.\n", piece.DisplayName())
+				}
+			}
 
 			switch action {
 			case "Spell":
@@ -234,11 +291,24 @@ func StartFight(character *structures.Player, enemy *structures.Enemy) bool {
 
 	if character.Alive {
 		fmt.Printf("\n%s has defeated %s!\n", character.Entity.Name, enemy.Entity.Name)
-		loot := structures.GenerateLootFromEnemy(enemy.EnemyRace)
-		character.AddItem(loot)
-		droppedMoney := structures.GetRNG().Intn(30) + 1
+		drops := structures.GenerateLoot(enemy)
+		if scroll, ok := structures.RollRecipeScroll(character, enemy.IsBoss); ok {
+			drops = append(drops, scroll)
+		}
+		droppedMoney := structures.RollGold(enemy.Depth, enemy.IsBoss)
 		character.Money += droppedMoney
-		fmt.Printf("%s found a %s, aswell as %d coins!\n", character.Entity.Name, loot.GetItem().Name, droppedMoney)
+		received, leftBehind := character.AddItems(drops)
+		fmt.Printf("%s found %d coins and:\n", character.Entity.Name, droppedMoney)
+		for _, entry := range received {
+			fmt.Printf("  + %s\n", entry.GetItem().Name)
+		}
+		if len(leftBehind) > 0 {
+			fmt.Println("Too heavy to carry, dropped on the floor:")
+			for _, entry := range leftBehind {
+				fmt.Printf("  - %s\n", entry.GetItem().Name)
+			}
+			character.DropOnFloor(leftBehind)
+		}
 		structures.RefreshSeedState()
 		character.AddXP(character.GetxpFromMob(enemy.Entity))
 	} else {
@@ -24,7 +24,7 @@ func RenderFight(player *structures.Player, mob *structures.Enemy, playerPlaying
 		return strings.Repeat(" ", padding) + text
 	}
 
-	makePlayerBox := func(name string, hp, maxHP, level, mana, defense int, weapon string) []string {
+	makePlayerBox := func(name string, hp, maxHP, level, mana, defense int, weapon, armor string) []string {
 		lines := []string{}
 		lines = append(lines, fmt.Sprintf("+%s+", strings.Repeat("-", boxWidth-2)))
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, name))
@@ -33,6 +33,7 @@ func RenderFight(player *structures.Player, mob *structures.Enemy, playerPlaying
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, fmt.Sprintf("Level: %d", level)))
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, fmt.Sprintf("Defense: %d%%", defense)))
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, fmt.Sprintf("Weapon: %s", weapon)))
+		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, fmt.Sprintf("Armor: %s", armor)))
 		lines = append(lines, fmt.Sprintf("+%s+", strings.Repeat("-", boxWidth-2)))
 		return lines
 	}
@@ -57,12 +58,13 @@ func RenderFight(player *structures.Player, mob *structures.Enemy, playerPlaying
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, levelIndicator))
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, fmt.Sprintf("Defense: %d%%", defense)))
 		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, fmt.Sprintf("Weapon: %s", weapon)))
+		lines = append(lines, fmt.Sprintf("| %-*s|", boxWidth-3, "")) // Same height as the player box
 		lines = append(lines, fmt.Sprintf("+%s+", strings.Repeat("-", boxWidth-2)))
 		return lines
 	}
 
-	playerDefenseRaw := player.Helmet.Defense + player.Chestplate.Defense + player.Boots.Defense + structures.GetSetBonusDefense(player.Entity)
-	mobDefenseRaw := mob.Helmet.Defense + mob.Chestplate.Defense + mob.Boots.Defense + structures.GetSetBonusDefense(mob.Entity)
+	playerDefenseRaw := player.Entity.TotalDefense()
+	mobDefenseRaw := mob.Entity.TotalDefense()
 
 	playerDefensePercent := playerDefenseRaw * 2
 	if playerDefensePercent > 85 {
@@ -73,7 +75,7 @@ func RenderFight(player *structures.Player, mob *structures.Enemy, playerPlaying
 		mobDefensePercent = 85
 	}
 
-	playerBox := makePlayerBox(player.Entity.Name, player.HP, player.MaxHP, player.Level, player.Mana, playerDefensePercent, player.Weapon.Name)
+	playerBox := makePlayerBox(player.Entity.Name, player.HP, player.MaxHP, player.Level, player.Mana, playerDefensePercent, weaponCondition(player.Weapon), armorCondition(player.Entity))
 	mobBox := makeEnemyBox(mob.Entity.Name, mob.HP, mob.MaxHP, mob.Level, mob.Mana, mobDefensePercent, mob.Weapon.Name)
 
 	padding := strings.Repeat(" ", leftPadding)
@@ -86,4 +88,36 @@ func RenderFight(player *structures.Player, mob *structures.Enemy, playerPlaying
 	for i := range playerBox {
 		fmt.Println(padding + playerBox[i] + strings.Repeat(" ", spaceBetween) + mobBox[i])
 	}
+	for _, line := range player.Entity.SetProgress() {
+		fmt.Println(padding + "Set " + line)
+	}
+}
+
+func weaponCondition(w structures.Weapon) string {
+	if w.IsBroken() {
+		return w.Name + " [BROKEN]"
+	}
+	if w.MaxDurability <= 0 {
+		return w.Name
+	}
+	return fmt.Sprintf("%s [%d/%d]", w.Name, w.Durability, w.MaxDurability)
+}
+
+// Sums the durability of the equipped pieces
+func armorCondition(ent structures.Entity) string {
+	durability, maxDurability, broken := 0, 0, 0
+	for _, piece := range ent.EquippedArmor() {
+		durability += piece.Durability
+		maxDurability += piece.MaxDurability
+		if piece.IsBroken() {
+			broken++
+		}
+	}
+	if maxDurability == 0 {
+		return "None"
+	}
+	if broken > 0 {
+		return fmt.Sprintf("%d/%d (%d broken)", durability, maxDurability, broken)
+	}
+	return fmt.Sprintf("%d/%d", durability, maxDurability)
 }
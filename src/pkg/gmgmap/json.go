@@ -0,0 +1,44 @@
+package gmgmap
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Layers are saved as rows of text, a rune list would be one number per tile
+type layerJSON struct {
+	Name   string
+	Width  int
+	Height int
+	Rows   []string
+}
+
+// MarshalJSON - save the layer tiles as rows of text
+func (l Layer) MarshalJSON() ([]byte, error) {
+	rows := make([]string, l.Height)
+	for y := 0; y < l.Height; y++ {
+		rows[y] = string(l.Tiles[y*l.Width : (y+1)*l.Width])
+	}
+	return json.Marshal(layerJSON{Name: l.Name, Width: l.Width, Height: l.Height, Rows: rows})
+}
+
+// UnmarshalJSON - rebuild the tiles from the rows of text
+func (l *Layer) UnmarshalJSON(data []byte) error {
+	var saved layerJSON
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	if len(saved.Rows) != saved.Height {
+		return errors.New("layer " + saved.Name + " has the wrong number of rows")
+	}
+	l.Name, l.Width, l.Height = saved.Name, saved.Width, saved.Height
+	l.Tiles = make([]rune, 0, saved.Width*saved.Height)
+	for _, row := range saved.Rows {
+		tiles := []rune(row)
+		if len(tiles) != saved.Width {
+			return errors.New("layer " + saved.Name + " has a row of the wrong width")
+		}
+		l.Tiles = append(l.Tiles, tiles...)
+	}
+	return nil
+}
@@ -25,74 +25,96 @@ type Map struct {
 
 // Tile types
 const (
-	nothing    = ' '
-	floor      = 'f'
-	road       = 'r'
-	road2      = 'R'
-	wall       = 'w'
-	wall2      = 'W'
-	room       = '.'
-	room2      = '#'
-	door       = '+'
-	doorLocked = 'x'
-	stairsUp   = '<'
-	stairsDown = '>'
-	tree       = 'T'
-	grass      = 'g'
-	sign       = 's'
-	hanging    = 'h'
-	window     = 'o'
-	counter    = '_'
-	shopkeeper = 'A'
-	shelf      = 'S'
-	stock      = ')'
-	table      = 't'
-	chair      = 'c'
-	rug        = '~'
-	pot        = '{'
-	assistant  = 'a'
-	flower     = 'v'
+	nothing      = ' '
+	floor        = 'f'
+	road         = 'r'
+	road2        = 'R'
+	wall         = 'w'
+	wall2        = 'W'
+	room         = '.'
+	room2        = '#'
+	door         = '+'
+	doorLocked   = 'x'
+	doorOpen     = '/'
+	trapSpikes   = '^'
+	trapGas      = '%'
+	trapAlarm    = '!'
+	trapTeleport = '*'
+	stairsUp     = '<'
+	stairsDown   = '>'
+	tree         = 'T'
+	grass        = 'g'
+	sign         = 's'
+	hanging      = 'h'
+	window       = 'o'
+	counter      = '_'
+	shopkeeper   = 'A'
+	shelf        = 'S'
+	stock        = ')'
+	table        = 't'
+	chair        = 'c'
+	rug          = '~'
+	pot          = '{'
+	assistant    = 'a'
+	flower       = 'v'
 
 	// Entities
 	player     = '@'
 	mob        = 'M'
 	merchant   = '$'
 	blacksmith = 'B'
+	alchemist  = 'L'
+	chest      = 'C'
+	stash      = 'H'
+	portal     = 'P'
+	lootPile   = 'l'
+	villager   = 'V'
 )
 
 // Exported tile constants for external use
 const (
-	Nothing    = nothing
-	Floor      = floor
-	Road       = road
-	Road2      = road2
-	Wall       = wall
-	Wall2      = wall2
-	Room       = room
-	Room2      = room2
-	Door       = door
-	DoorLocked = doorLocked
-	StairsUp   = stairsUp
-	StairsDown = stairsDown
-	Tree       = tree
-	Grass      = grass
-	Sign       = sign
-	Hanging    = hanging
-	Window     = window
-	Counter    = counter
-	Shopkeeper = shopkeeper
-	Shelf      = shelf
-	Stock      = stock
-	Table      = table
-	Chair      = chair
-	Rug        = rug
-	Pot        = pot
-	Assistant  = assistant
-	Flower     = flower
-	Player     = player
-	Mob        = mob
-	Merchant   = merchant
-	Blacksmith = blacksmith
+	Nothing      = nothing
+	Floor        = floor
+	Road         = road
+	Road2        = road2
+	Wall         = wall
+	Wall2        = wall2
+	Room         = room
+	Room2        = room2
+	Door         = door
+	DoorLocked   = doorLocked
+	DoorOpen     = doorOpen
+	TrapSpikes   = trapSpikes
+	TrapGas      = trapGas
+	TrapAlarm    = trapAlarm
+	TrapTeleport = trapTeleport
+	StairsUp     = stairsUp
+	StairsDown   = stairsDown
+	Tree         = tree
+	Grass        = grass
+	Sign         = sign
+	Hanging      = hanging
+	Window       = window
+	Counter      = counter
+	Shopkeeper   = shopkeeper
+	Shelf        = shelf
+	Stock        = stock
+	Table        = table
+	Chair        = chair
+	Rug          = rug
+	Pot          = pot
+	Assistant    = assistant
+	Flower       = flower
+	Player       = player
+	Mob          = mob
+	Merchant     = merchant
+	Blacksmith   = blacksmith
+	Alchemist    = alchemist
+	Chest        = chest
+	Stash        = stash
+	Portal       = portal
+	LootPile     = lootPile
+	Villager     = villager
 )
 
 // NewMap - create a new Map for a certain size
@@ -133,6 +155,11 @@ func (m *Map) removeLayer(name string) {
 	}
 }
 
+// RemoveLayer - exported version of removeLayer for external access
+func (m *Map) RemoveLayer(name string) {
+	m.removeLayer(name)
+}
+
 func (l Layer) getTile(x, y int) rune {
 	if x < 0 || x >= l.Width || y < 0 || y >= l.Height {
 		return rune(0)
@@ -261,6 +288,16 @@ func getTileSymbol(tile rune) string {
 		return color.New(color.FgYellow, color.Bold).Sprint("▒")
 	case doorLocked:
 		return color.New(color.FgRed, color.Bold).Sprint("▓")
+	case doorOpen:
+		return color.New(color.FgYellow).Sprint("'")
+	case trapSpikes:
+		return color.New(color.FgRed, color.Bold).Sprint("^")
+	case trapGas:
+		return color.New(color.FgGreen, color.Bold).Sprint("%")
+	case trapAlarm:
+		return color.New(color.FgYellow, color.Bold).Sprint("!")
+	case trapTeleport:
+		return color.New(color.FgMagenta, color.Bold).Sprint("*")
 	case stairsUp:
 		return color.New(color.FgGreen, color.Bold).Sprint("▲")
 	case stairsDown:
@@ -303,6 +340,18 @@ func getTileSymbol(tile rune) string {
 		return color.New(color.FgYellow, color.Bold).Sprint("👑")
 	case blacksmith:
 		return color.New(color.FgCyan, color.Bold).Sprint("⚒️")
+	case alchemist:
+		return color.New(color.FgMagenta, color.Bold).Sprint("🧪")
+	case chest:
+		return color.New(color.FgYellow).Sprint("📦")
+	case stash:
+		return color.New(color.FgCyan).Sprint("🏦")
+	case portal:
+		return color.New(color.FgBlue, color.Bold).Sprint("🌀")
+	case lootPile:
+		return color.New(color.FgYellow).Sprint("💰")
+	case villager:
+		return color.New(color.FgWhite).Sprint("🧑")
 	default:
 		return color.WhiteString(string(tile))
 	}
@@ -316,7 +365,7 @@ func GetTileSymbol(tile rune) string {
 // IsDoubleWidthEntity - check if a tile is a double-width emoji entity
 func IsDoubleWidthEntity(tile rune) bool {
 	switch tile {
-	case player, mob, merchant, blacksmith:
+	case player, mob, merchant, blacksmith, alchemist, chest, stash, portal, lootPile, villager:
 		return true
 	default:
 		return false
@@ -347,6 +396,36 @@ func GetEntitySymbolWithBackground(entityTile, groundTile rune) string {
 			return color.New(color.FgCyan, color.Bold, color.BgHiBlack).Sprint("⚒️")
 		}
 		return color.New(color.FgCyan, color.Bold).Sprint("⚒️")
+	case alchemist:
+		if groundTile == room || groundTile == room2 {
+			return color.New(color.FgMagenta, color.Bold, color.BgHiBlack).Sprint("🧪")
+		}
+		return color.New(color.FgMagenta, color.Bold).Sprint("🧪")
+	case chest:
+		if groundTile == room || groundTile == room2 {
+			return color.New(color.FgYellow, color.BgHiBlack).Sprint("📦")
+		}
+		return color.New(color.FgYellow).Sprint("📦")
+	case stash:
+		if groundTile == room || groundTile == room2 {
+			return color.New(color.FgCyan, color.BgHiBlack).Sprint("🏦")
+		}
+		return color.New(color.FgCyan).Sprint("🏦")
+	case portal:
+		if groundTile == room || groundTile == room2 {
+			return color.New(color.FgBlue, color.Bold, color.BgHiBlack).Sprint("🌀")
+		}
+		return color.New(color.FgBlue, color.Bold).Sprint("🌀")
+	case lootPile:
+		if groundTile == room || groundTile == room2 {
+			return color.New(color.FgYellow, color.BgHiBlack).Sprint("💰")
+		}
+		return color.New(color.FgYellow).Sprint("💰")
+	case villager:
+		if groundTile == room || groundTile == room2 {
+			return color.New(color.FgWhite, color.BgHiBlack).Sprint("🧑")
+		}
+		return color.New(color.FgWhite).Sprint("🧑")
 	default:
 		return getTileSymbol(entityTile)
 	}
@@ -484,7 +563,12 @@ func IsWall(tile rune) bool {
 
 // IsDoor - whether a tile is a door type
 func IsDoor(tile rune) bool {
-	return tile == door || tile == doorLocked
+	return tile == door || tile == doorLocked || tile == doorOpen
+}
+
+// IsTrap - whether a tile is a trap type
+func IsTrap(tile rune) bool {
+	return tile == trapSpikes || tile == trapGas || tile == trapAlarm || tile == trapTeleport
 }
 
 // IsStairs - whether a tile is stairs
@@ -572,9 +656,10 @@ func addCorridor(g, s *Layer, startX, startY, endX, endY int, tile rune) {
 
 // Tile - Single tile on the map for astar
 type Tile struct {
-	x, y int
-	s    *Layer
-	w    World
+	x, y     int
+	s        *Layer
+	w        World
+	passable func(x, y int) bool // Replaces the structure layer check when set
 }
 
 // PathNeighbors - Get neighbours for astar pathfinding
@@ -586,8 +671,18 @@ func (t *Tile) PathNeighbors() []astar.Pather {
 		{0, -1},
 		{0, 1},
 	} {
-		if n := t.s.getTile(t.x+offset[0], t.y+offset[1]); n == nothing {
-			neighbors = append(neighbors, t.w.tile(t.x+offset[0], t.y+offset[1]))
+		x, y := t.x+offset[0], t.y+offset[1]
+		if t.passable != nil {
+			if t.passable(x, y) {
+				n := t.w.tile(x, y)
+				if n == nil { // Tiles are only made once the search reaches them
+					n = &Tile{passable: t.passable}
+					t.w.setTile(n, x, y)
+				}
+				neighbors = append(neighbors, n)
+			}
+		} else if n := t.s.getTile(x, y); n == nothing {
+			neighbors = append(neighbors, t.w.tile(x, y))
 		}
 	}
 	return neighbors
@@ -630,8 +725,38 @@ func addPath(g, s *Layer, x1, y1, x2, y2 int) (path []astar.Pather, distance flo
 	w := World{}
 	for x := 0; x < g.Width; x++ {
 		for y := 0; y < g.Height; y++ {
-			w.setTile(&Tile{x, y, s, w}, x, y)
+			w.setTile(&Tile{x: x, y: y, s: s, w: w}, x, y)
 		}
 	}
 	return astar.Path(w.tile(x1, y1), w.tile(x2, y2))
 }
+
+// FindPath - Use A* to find a path between two points, only stepping on tiles passable returns true for
+// The path goes from the first point to the second, both included
+// Only the tiles the search reaches are allocated, so short paths stay cheap on big maps
+func FindPath(width, height int, passable func(x, y int) bool, x1, y1, x2, y2 int) (path [][2]int, found bool) {
+	inside := func(x, y int) bool {
+		return x >= 0 && x < width && y >= 0 && y < height
+	}
+	if !inside(x1, y1) || !inside(x2, y2) {
+		return nil, false
+	}
+	bounded := func(x, y int) bool {
+		return inside(x, y) && passable(x, y)
+	}
+	w := World{}
+	from, to := &Tile{passable: bounded}, &Tile{passable: bounded}
+	w.setTile(from, x1, y1)
+	if x1 != x2 || y1 != y2 {
+		w.setTile(to, x2, y2)
+	} else {
+		to = from
+	}
+	pathers, _, found := astar.Path(from, to)
+	// astar returns the path from the end
+	for i := len(pathers) - 1; i >= 0; i-- {
+		t := pathers[i].(*Tile)
+		path = append(path, [2]int{t.x, t.y})
+	}
+	return path, found
+}
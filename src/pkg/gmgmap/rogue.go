@@ -107,6 +107,19 @@ func NewRogue(rr *rand.Rand, width, height,
 			gridIndex = rr.Intn(len(connected))
 			grid.x = gridIndex % gridWidth
 			grid.y = gridIndex / gridWidth
+			// Edges count as connected, else the walk below never ends
+			if grid.x == 0 {
+				connected[gridIndex].left = true
+			}
+			if grid.y == 0 {
+				connected[gridIndex].up = true
+			}
+			if grid.x == gridWidth-1 {
+				connected[gridIndex].right = true
+			}
+			if grid.y == gridHeight-1 {
+				connected[gridIndex].down = true
+			}
 			if connected[gridIndex].allConnected() {
 				break
 			}
@@ -171,7 +184,7 @@ func NewRogue(rr *rand.Rand, width, height,
 	// Connect each room to connected neighbours
 	for i := 0; i < totalGrids; i++ {
 		connections := connected[i]
-		x, y := i%gridWidth, i/gridHeight
+		x, y := i%gridWidth, i/gridWidth
 		roomRect := rooms[i]
 		// Only connect to the right and below
 		if connections.right && x < gridWidth-1 {
@@ -0,0 +1,75 @@
+package gmgmap
+
+// FindDeadEndRooms - find the rooms that have a single opening
+// Returns the spot furthest from the opening in each of those rooms,
+// with enough room for a 2-wide entity and not below maxY
+func FindDeadEndRooms(m *Map, maxY int) [][2]int {
+	g := m.Layer("Ground")
+	s := m.Layer("Structures")
+	seen := make([]bool, len(g.Tiles))
+	var spots [][2]int
+	for start := range g.Tiles {
+		if seen[start] || g.Tiles[start] != room || IsWall(s.Tiles[start]) {
+			continue
+		}
+
+		// Flood the room, openings are the corridor tiles touching it
+		region := []int{start}
+		inRegion := map[int]bool{start: true}
+		openings := map[int]bool{}
+		seen[start] = true
+		for i := 0; i < len(region); i++ {
+			x, y := region[i]%g.Width, region[i]/g.Width
+			for _, d := range []vec2{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+				nx, ny := x+d.x, y+d.y
+				if !g.isIn(nx, ny) {
+					continue
+				}
+				index := nx + ny*g.Width
+				if IsWall(s.Tiles[index]) {
+					continue
+				}
+				if g.Tiles[index] == room && !seen[index] {
+					seen[index] = true
+					inRegion[index] = true
+					region = append(region, index)
+				} else if g.Tiles[index] == room2 {
+					openings[index] = true
+				}
+			}
+		}
+
+		// Openings are 2 tiles wide, count each group of tiles once
+		groups := 0
+		opening := -1
+		for index := range openings {
+			x := index % g.Width
+			if (x == 0 || !openings[index-1]) && !openings[index-g.Width] {
+				groups++
+			}
+			if opening == -1 || index < opening {
+				opening = index
+			}
+		}
+		if groups != 1 {
+			continue
+		}
+
+		best, bestDist := -1, -1
+		ox, oy := opening%g.Width, opening/g.Width
+		for _, index := range region {
+			x, y := index%g.Width, index/g.Width
+			if y > maxY || !inRegion[index+1] || x+1 >= g.Width || s.Tiles[index] != nothing || s.Tiles[index+1] != nothing {
+				continue
+			}
+			dist := iabs(x-ox) + iabs(y-oy)
+			if dist > bestDist {
+				best, bestDist = index, dist
+			}
+		}
+		if best >= 0 {
+			spots = append(spots, [2]int{best % g.Width, best / g.Width})
+		}
+	}
+	return spots
+}
@@ -76,6 +76,13 @@ func imax(i1, i2 int) int {
 	return i2
 }
 
+func iabs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
 func iclamp(v, min, max int) int {
 	switch {
 	case v < min:
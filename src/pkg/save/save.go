@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
 )
 
 var SaveId string
@@ -78,3 +79,20 @@ func LoadWorldState() (WorldState, error) {
 	}
 	return ws, nil
 }
+
+// Removes every save file whose name starts with the prefix, eg. all the dungeon levels
+func DeleteAll(prefix string) error {
+	if SaveId == "" {
+		return errors.New("save id is not set")
+	}
+	paths, err := filepath.Glob("saves/" + SaveId + "/" + prefix + "*.json")
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
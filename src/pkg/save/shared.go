@@ -0,0 +1,36 @@
+package save
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Account-wide files sit next to the character folders so every character can reach them
+func sharedPath(fileName string) string {
+	return "saves/shared_" + fileName + ".json"
+}
+
+func SaveShared(fileName string, obj interface{}) error {
+	if err := os.MkdirAll("saves", 0755); err != nil {
+		return err
+	}
+	jsonFile, err := os.Create(sharedPath(fileName))
+	if err != nil {
+		return err
+	}
+	defer jsonFile.Close()
+
+	encoder := json.NewEncoder(jsonFile)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(obj)
+}
+
+func LoadShared(fileName string, obj interface{}) error {
+	jsonFile, err := os.Open(sharedPath(fileName))
+	if err != nil {
+		return err
+	}
+	defer jsonFile.Close()
+
+	return json.NewDecoder(jsonFile).Decode(obj)
+}
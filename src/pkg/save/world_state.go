@@ -1,13 +1,8 @@
 package save
 
-type Position struct {
-	X int `json:"x"`
-	Y int `json:"y"`
-}
-
+// Mobs, chests and the layout live in the saved levels, see display/levels.go
 type WorldState struct {
-	CurrentLevel int        `json:"currentLevel"`
-	PlayerX      int        `json:"playerX"`
-	PlayerY      int        `json:"playerY"`
-	Mobs         []Position `json:"mobs"`
+	CurrentLevel int `json:"currentLevel"`
+	PlayerX      int `json:"playerX"`
+	PlayerY      int `json:"playerY"`
 }
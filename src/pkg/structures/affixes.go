@@ -0,0 +1,198 @@
+package structures
+
+type Affix struct {
+	Name   string
+	Prefix bool   // Prefixes go before the item name, suffixes after ("of the Bear")
+	Stat   string // Lifesteal | FireDamage | IceDamage | LightningDamage | BonusMana | Initiative | Defense
+	Value  int
+}
+
+type affixTemplate struct {
+	Affix
+	Min    int
+	Max    int
+	Weapon bool // Can roll on weapons
+	Armor  bool // Can roll on armors
+}
+
+var affixPool = []affixTemplate{
+	{Affix: Affix{Name: "Burning", Prefix: true, Stat: "FireDamage"}, Min: 3, Max: 8, Weapon: true},
+	{Affix: Affix{Name: "Frozen", Prefix: true, Stat: "IceDamage"}, Min: 3, Max: 8, Weapon: true},
+	{Affix: Affix{Name: "Shocking", Prefix: true, Stat: "LightningDamage"}, Min: 2, Max: 10, Weapon: true},
+	{Affix: Affix{Name: "Vampiric", Prefix: true, Stat: "Lifesteal"}, Min: 5, Max: 15, Weapon: true}, // % of damage dealt
+	{Affix: Affix{Name: "Swift", Prefix: true, Stat: "Initiative"}, Min: 2, Max: 6, Weapon: true, Armor: true},
+	{Affix: Affix{Name: "Sturdy", Prefix: true, Stat: "Defense"}, Min: 1, Max: 3, Armor: true},
+	{Affix: Affix{Name: "of the Bear", Stat: "Defense"}, Min: 1, Max: 4, Armor: true},
+	{Affix: Affix{Name: "of the Sage", Stat: "BonusMana"}, Min: 10, Max: 30, Weapon: true, Armor: true},
+	{Affix: Affix{Name: "of the Fox", Stat: "Initiative"}, Min: 3, Max: 8, Weapon: true, Armor: true},
+	{Affix: Affix{Name: "of Embers", Stat: "FireDamage"}, Min: 2, Max: 6, Weapon: true},
+}
+
+var Tiers = []string{"Common", "Uncommon", "Rare", "Epic", "Legendary"}
+
+var tierWeight = map[string]int{
+	"Common":    50,
+	"Uncommon":  25,
+	"Rare":      15,
+	"Epic":      7,
+	"Legendary": 3,
+}
+
+// Items get at most one prefix and one suffix, higher tiers roll stronger values
+var tierAffixCount = map[string]int{
+	"Common":    0,
+	"Uncommon":  1,
+	"Rare":      2,
+	"Epic":      2,
+	"Legendary": 2,
+}
+
+var tierValueBonus = map[string]float64{
+	"Common":    1.0,
+	"Uncommon":  1.0,
+	"Rare":      1.0,
+	"Epic":      1.5,
+	"Legendary": 2.0,
+}
+
+func TierIndex(tier string) int {
+	for i, t := range Tiers {
+		if t == tier {
+			return i
+		}
+	}
+	return 0
+}
+
+func rollTier() string {
+	total := 0
+	for _, t := range Tiers {
+		total += tierWeight[t]
+	}
+	r := GetRNG().Intn(total)
+	cumulative := 0
+	for _, t := range Tiers {
+		cumulative += tierWeight[t]
+		if r < cumulative {
+			return t
+		}
+	}
+	return "Common"
+}
+
+// Rolls up to one prefix and one suffix for the tier
+func rollAffixes(tier string, forWeapon bool) []Affix {
+	rng := GetRNG()
+	affixes := []Affix{}
+	hasPrefix, hasSuffix := false, false
+	for tries := 0; len(affixes) < tierAffixCount[tier] && tries < 20; tries++ {
+		tpl := affixPool[rng.Intn(len(affixPool))]
+		if (forWeapon && !tpl.Weapon) || (!forWeapon && !tpl.Armor) {
+			continue
+		}
+		if (tpl.Prefix && hasPrefix) || (!tpl.Prefix && hasSuffix) {
+			continue
+		}
+		affix := tpl.Affix
+		affix.Value = int(float64(tpl.Min+rng.Intn(tpl.Max-tpl.Min+1)) * tierValueBonus[tier])
+		if affix.Prefix {
+			hasPrefix = true
+		} else {
+			hasSuffix = true
+		}
+		affixes = append(affixes, affix)
+	}
+	return affixes
+}
+
+func RollWeapon(weapon Weapon) Weapon {
+	weapon.Tier = rollTier()
+	weapon.Affixes = rollAffixes(weapon.Tier, true)
+	RefreshSeedState()
+	return weapon
+}
+
+func RollArmor(armor Armors) Armors {
+	if armor.Name == "None" {
+		return armor
+	}
+	armor.Tier = rollTier()
+	armor.Affixes = rollAffixes(armor.Tier, false)
+	RefreshSeedState()
+	return armor
+}
+
+func affixTotal(affixes []Affix, stat string) int {
+	total := 0
+	for _, a := range affixes {
+		if a.Stat == stat {
+			total += a.Value
+		}
+	}
+	return total
+}
+
+// Builds "Prefix base suffix" names, eg "Burning Sword of the Fox"
+func affixName(base string, affixes []Affix) string {
+	name := base
+	for _, a := range affixes {
+		if a.Prefix {
+			name = a.Name + " " + name
+		} else {
+			name += " " + a.Name
+		}
+	}
+	return name
+}
+
+func (w Weapon) AffixTotal(stat string) int {
+	return affixTotal(w.Affixes, stat)
+}
+
+// Extra damage from element affixes
+func (w Weapon) ElementDamage() int {
+	return w.AffixTotal("FireDamage") + w.AffixTotal("IceDamage") + w.AffixTotal("LightningDamage")
+}
+
+func (w Weapon) DisplayName() string {
+	if w.Unidentified {
+		return "Unidentified " + w.Category
+	}
+	return affixName(w.Name, w.Affixes)
+}
+
+func (a Armors) AffixTotal(stat string) int {
+	return affixTotal(a.Affixes, stat) + affixTotal(a.BaseStats, stat)
+}
+
+func (a Armors) DisplayName() string {
+	if a.Unidentified {
+		return "Unidentified " + a.Type
+	}
+	return affixName(a.Type+" "+a.Name, a.Affixes)
+}
+
+// Sums an affix stat over all the equipped armor pieces
+func (ent *Entity) ArmorAffixTotal(stat string) int {
+	total := 0
+	for _, piece := range ent.EquippedArmor() {
+		total += piece.AffixTotal(stat)
+	}
+	return total
+}
+
+// Gives back a part of the damage dealt, returns the amount healed
+func (ent *Entity) applyLifesteal(percent int, damageDealt int) int {
+	if percent <= 0 || damageDealt <= 0 {
+		return 0
+	}
+	heal := damageDealt * percent / 100
+	if heal < 1 {
+		heal = 1
+	}
+	if ent.HP+heal > ent.MaxHP {
+		heal = ent.MaxHP - ent.HP
+	}
+	ent.HP += heal
+	return heal
+}
@@ -0,0 +1,103 @@
+package structures
+
+type BrewRecipe struct {
+	Key         string
+	Potion      string // Key in AllPotions
+	Ingredients map[string]int
+}
+
+var AllBrewRecipes = []BrewRecipe{
+	{Key: "BrewHeal", Potion: "Heal", Ingredients: map[string]int{"Moonleaf": 2}},
+	{Key: "BrewMana", Potion: "Mana", Ingredients: map[string]int{"Glowmoss": 2}},
+	{Key: "BrewCure", Potion: "Cure", Ingredients: map[string]int{"Moonleaf": 2, "Glowmoss": 1}},
+	{Key: "BrewStrength", Potion: "Strength", Ingredients: map[string]int{"Bloodroot": 1, "OrcTusk": 1}},
+	{Key: "BrewResistance", Potion: "Resistance", Ingredients: map[string]int{"Ashcap": 1, "SkeletonBone": 1}},
+	{Key: "BrewHaste", Potion: "Haste", Ingredients: map[string]int{"Moonleaf": 1, "GoblinEar": 1}},
+}
+
+var herbKeys = []string{"Moonleaf", "Glowmoss", "Bloodroot", "Ashcap"}
+
+func GetBrewRecipe(key string) (BrewRecipe, bool) {
+	for _, r := range AllBrewRecipes {
+		if r.Key == key {
+			return r, true
+		}
+	}
+	return BrewRecipe{}, false
+}
+
+// Finds the recipe matching exactly the ingredients put in the cauldron
+func findBrewRecipe(ingredients map[string]int) (BrewRecipe, bool) {
+	for _, r := range AllBrewRecipes {
+		if len(r.Ingredients) != len(ingredients) {
+			continue
+		}
+		match := true
+		for key, amt := range r.Ingredients {
+			if ingredients[key] != amt {
+				match = false
+				break
+			}
+		}
+		if match {
+			return r, true
+		}
+	}
+	return BrewRecipe{}, false
+}
+
+// Whether the ingredients are part of a recipe, used to hint the player on failed brews
+func isPartialBrew(ingredients map[string]int) bool {
+	for _, r := range AllBrewRecipes {
+		partial := true
+		for key, amt := range ingredients {
+			if r.Ingredients[key] < amt {
+				partial = false
+				break
+			}
+		}
+		if partial {
+			return true
+		}
+	}
+	return false
+}
+
+func (plr *Player) KnowsBrew(key string) bool {
+	for _, k := range plr.KnownBrews {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+type BrewResult struct {
+	Success    bool
+	Discovered bool // First time this recipe was brewed
+	Close      bool // Failed, but the ingredients belong to a recipe
+	Potion     Potion
+}
+
+// Brews the given ingredients, they are consumed even if the brew fails
+func (plr *Player) Brew(ingredients map[string]int) (BrewResult, bool) {
+	if len(ingredients) == 0 || !plr.HasMaterialsBatch(ingredients) {
+		return BrewResult{}, false
+	}
+	recipe, found := findBrewRecipe(ingredients)
+	if found {
+		potion := NewInstance(AllPotions[recipe.Potion]).(Potion)
+		if !plr.CanAddItem(potion) {
+			return BrewResult{}, false
+		}
+		plr.RemoveMaterialsBatch(ingredients)
+		plr.AddItem(potion)
+		discovered := !plr.KnowsBrew(recipe.Key)
+		if discovered {
+			plr.KnownBrews = append(plr.KnownBrews, recipe.Key)
+		}
+		return BrewResult{Success: true, Discovered: discovered, Potion: potion}, true
+	}
+	plr.RemoveMaterialsBatch(ingredients)
+	return BrewResult{Close: isPartialBrew(ingredients)}, true
+}
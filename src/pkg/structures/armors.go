@@ -4,24 +4,58 @@ type Armors struct {
 	Name    string
 	Type    string
 	Defense int
+	Tier    string
+	Affixes []Affix
+
+	BaseStats   []Affix // Fixed stats of rings and amulets
+	BlockChance int     // Shields only, % chance to absorb half of a hit
+
+	Unidentified bool // Name and stats hidden until identified
+	Cursed       bool // Can't be unequipped until the curse is lifted
+
+	Durability    int
+	MaxDurability int
 }
 
 var (
-	HelmetStormBringer     = Armors{Name: "StormBringer", Type: "Helmet", Defense: 6}
-	HelmetSunBreaker       = Armors{Name: "SunBreaker", Type: "Helmet", Defense: 4}
-	HelmetVoidWalker       = Armors{Name: "VoidWalker", Type: "Helmet", Defense: 2}
-	ChestplateStormBringer = Armors{Name: "StormBringer", Type: "Chestplate", Defense: 12}
-	ChestplateSunBreaker   = Armors{Name: "SunBreaker", Type: "Chestplate", Defense: 8}
-	ChestplateVoidWalker   = Armors{Name: "VoidWalker", Type: "Chestplate", Defense: 4}
-	BootsStormBringer      = Armors{Name: "StormBringer", Type: "Boots", Defense: 6}
-	BootsSunBreaker        = Armors{Name: "SunBreaker", Type: "Boots", Defense: 4}
-	BootsVoidWalker        = Armors{Name: "VoidWalker", Type: "Boots", Defense: 2}
+	HelmetStormBringer     = Armors{Name: "StormBringer", Type: "Helmet", Defense: 6, Durability: 120, MaxDurability: 120}
+	HelmetSunBreaker       = Armors{Name: "SunBreaker", Type: "Helmet", Defense: 4, Durability: 90, MaxDurability: 90}
+	HelmetVoidWalker       = Armors{Name: "VoidWalker", Type: "Helmet", Defense: 2, Durability: 60, MaxDurability: 60}
+	ChestplateStormBringer = Armors{Name: "StormBringer", Type: "Chestplate", Defense: 12, Durability: 120, MaxDurability: 120}
+	ChestplateSunBreaker   = Armors{Name: "SunBreaker", Type: "Chestplate", Defense: 8, Durability: 90, MaxDurability: 90}
+	ChestplateVoidWalker   = Armors{Name: "VoidWalker", Type: "Chestplate", Defense: 4, Durability: 60, MaxDurability: 60}
+	BootsStormBringer      = Armors{Name: "StormBringer", Type: "Boots", Defense: 6, Durability: 120, MaxDurability: 120}
+	BootsSunBreaker        = Armors{Name: "SunBreaker", Type: "Boots", Defense: 4, Durability: 90, MaxDurability: 90}
+	BootsVoidWalker        = Armors{Name: "VoidWalker", Type: "Boots", Defense: 2, Durability: 60, MaxDurability: 60}
+)
+
+// Jewelry and off-hand
+var (
+	RingSapphire   = Armors{Name: "Sapphire", Type: "Ring", BaseStats: []Affix{{Name: "Sapphire", Stat: "BonusMana", Value: 20}}}
+	RingEmerald    = Armors{Name: "Emerald", Type: "Ring", BaseStats: []Affix{{Name: "Emerald", Stat: "Initiative", Value: 4}}}
+	RingRuby       = Armors{Name: "Ruby", Type: "Ring", BaseStats: []Affix{{Name: "Ruby", Stat: "Lifesteal", Value: 5}}}
+	AmuletGuardian = Armors{Name: "Guardian", Type: "Amulet", BaseStats: []Affix{{Name: "Guardian", Stat: "Defense", Value: 3}}}
+	AmuletSage     = Armors{Name: "Sage", Type: "Amulet", BaseStats: []Affix{{Name: "Sage", Stat: "BonusMana", Value: 40}}}
+	ShieldBuckler  = Armors{Name: "Buckler", Type: "Shield", Defense: 2, BlockChance: 15, Durability: 60, MaxDurability: 60}
+	ShieldKite     = Armors{Name: "Kite", Type: "Shield", Defense: 4, BlockChance: 25, Durability: 90, MaxDurability: 90}
+	ShieldTower    = Armors{Name: "Tower", Type: "Shield", Defense: 6, BlockChance: 35, Durability: 120, MaxDurability: 120}
 )
 
-var setBonusDefense = map[string]int{
-	"StormBringer": 6,
-	"SunBreaker":   4,
-	"VoidWalker":   2,
+var AllRings = map[string]Armors{
+	"Sapphire": RingSapphire,
+	"Emerald":  RingEmerald,
+	"Ruby":     RingRuby,
+}
+
+var AllAmulets = map[string]Armors{
+	"Guardian": AmuletGuardian,
+	"Sage":     AmuletSage,
+}
+
+var AllShields = map[string]Armors{
+	"Buckler": ShieldBuckler,
+	"Kite":    ShieldKite,
+	"Tower":   ShieldTower,
 }
 
 var armorRarityWeight = map[string]int{
@@ -48,15 +82,6 @@ var AllBoots = map[string]Armors{
 	"VoidWalker":   BootsVoidWalker,
 }
 
-func GetSetBonusDefense(ent Entity) int {
-	if ent.Helmet.Name == ent.Chestplate.Name && ent.Chestplate.Name == ent.Boots.Name && ent.Helmet.Name != "None" {
-		if bonus, ok := setBonusDefense[ent.Helmet.Name]; ok {
-			return bonus
-		}
-	}
-	return 0
-}
-
 func getWeightedRandomName() string { // Get random armor name based on rarity
 	total := 0
 	for _, w := range armorRarityWeight {
@@ -78,8 +103,7 @@ func getWeightedRandomName() string { // Get random armor name based on rarity
 	return "VoidWalker"
 }
 
-func GetRandomArmorByType(armorType string) Armors {
-	name := getWeightedRandomName()
+func GetArmorByType(armorType, name string) Armors {
 	switch armorType {
 	case "Helmet":
 		return AllHelmets[name]
@@ -87,7 +111,21 @@ func GetRandomArmorByType(armorType string) Armors {
 		return AllChestplates[name]
 	case "Boots":
 		return AllBoots[name]
+	case "Ring":
+		return AllRings[name]
+	case "Amulet":
+		return AllAmulets[name]
+	case "Shield":
+		return AllShields[name]
 	default:
 		return Armors{Name: "None", Type: armorType, Defense: 0}
 	}
 }
+
+func NoArmor(armorType string) Armors {
+	return Armors{Name: "None", Type: armorType, Defense: 0}
+}
+
+func GetRandomArmorByType(armorType string) Armors {
+	return GetArmorByType(armorType, getWeightedRandomName())
+}
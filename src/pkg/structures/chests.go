@@ -0,0 +1,134 @@
+package structures
+
+type Chest struct {
+	X, Y     int
+	Locked   bool // Has a lock, locked chests hold more loot
+	Unlocked bool
+	Jammed   bool // A failed lockpick jams the lock, only a key opens it after that
+	Mimic    bool
+	Opened   bool
+	Vault    bool // Behind a locked door, better stocked
+}
+
+const (
+	chestLockedChance = 30
+	chestMimicChance  = 8 // Grows by 1 per depth level
+	maxMimicChance    = 20
+	maxLockpickChance = 85
+	vaultDepthBonus   = 3
+)
+
+var ChestKey = Material{
+	Item: NewItem("Rusty Key", 0, 40, 2),
+	Key:  "ChestKey",
+}
+
+// Locked chests roll one extra time and give twice the gold
+var ChestLootTable = []LootEntry{
+	{Kind: "herb", Weight: 25},
+	{Kind: "potion", Key: "Heal", Weight: 20},
+	{Kind: "potion", Key: "Mana", Weight: 12},
+	{Kind: "gear", Weight: 15, DepthBonus: 2},
+	{Kind: "accessory", Weight: 8, DepthBonus: 1},
+	{Kind: "material", Key: "ChestKey", Weight: 6},
+	{Kind: "material", Key: "VaultKey", Weight: 3, MinDepth: 1},
+	{Kind: "scroll", Key: "Identify", Weight: 6},
+	{Kind: "scroll", Key: "TownPortal", Weight: 5},
+	{Kind: "scroll", Key: "Teleport", Weight: 4},
+	{Kind: "scroll", Key: "MagicMapping", Weight: 3, MinDepth: 1},
+	{Kind: "potion", Key: "Strength", Weight: 4, MinDepth: 2, DepthBonus: 1},
+	{Kind: "spellbook", Key: "SpellBookFireball", Weight: 1, MinDepth: 3, DepthBonus: 1},
+}
+
+func RollChest(x, y, depth int) Chest {
+	rng := GetRNG()
+	mimicChance := chestMimicChance + depth
+	if mimicChance > maxMimicChance {
+		mimicChance = maxMimicChance
+	}
+	chest := Chest{X: x, Y: y}
+	if rng.Intn(100) < mimicChance {
+		chest.Mimic = true
+	} else if rng.Intn(100) < chestLockedChance {
+		chest.Locked = true
+	}
+	return chest
+}
+
+func (c Chest) IsLocked() bool {
+	return c.Locked && !c.Unlocked
+}
+
+// Rolls the content of the chest, returns the items and the gold found
+func (c Chest) Loot(depth int) ([]InventoryEntry, int) {
+	rolls := 2 + depth/3
+	if rolls > 4 {
+		rolls = 4
+	}
+	gold := RollGold(depth, false)
+	if c.Locked {
+		rolls++
+		gold *= 2
+	}
+	lootDepth := depth
+	if c.Vault { // Rolled as if deeper, rare drops are more common
+		rolls += 2
+		gold *= 3
+		lootDepth += vaultDepthBonus
+	}
+	drops := []InventoryEntry{}
+	for i := 0; i < rolls; i++ {
+		if e, ok := rollLootEntry(ChestLootTable, lootDepth); ok {
+			drops = append(drops, lootFromEntry(e, depth))
+		}
+	}
+	RefreshSeedState()
+	return drops, gold
+}
+
+func VaultChest(x, y int) Chest {
+	return Chest{X: x, Y: y, Vault: true}
+}
+
+func InitMimic(depth int) Enemy {
+	mimic := InitScaledEnemy("Mimic", "Mimic", depth)
+	mimic.Depth = depth
+	return mimic
+}
+
+func (plr *Player) HasKey() bool {
+	return plr.CountMaterial(ChestKey.Key) > 0
+}
+
+// Nimble and experienced characters are better at picking locks
+func (plr *Player) LockpickChance() int {
+	chance := 20 + plr.TotalInitiative() + plr.Entity.Level*2
+	if chance > maxLockpickChance {
+		chance = maxLockpickChance
+	}
+	return chance
+}
+
+// Opens a locked chest with a key or by picking the lock, returns false if it stays locked
+func (plr *Player) Unlock(c *Chest, useKey bool) bool {
+	if !c.IsLocked() {
+		return true
+	}
+	if useKey {
+		if plr.RemoveMaterials(ChestKey.Key, 1) == 0 {
+			return false
+		}
+		c.Unlocked = true
+		return true
+	}
+	if c.Jammed {
+		return false
+	}
+	if GetRNG().Intn(100) < plr.LockpickChance() {
+		c.Unlocked = true
+	} else {
+		c.Jammed = true
+	}
+	RefreshSeedState()
+	return c.Unlocked
+}
@@ -0,0 +1,54 @@
+package structures
+
+const (
+	eliteChance   = 12 // Percent of the dungeon enemies
+	minBashChance = 15
+	maxBashChance = 70
+)
+
+var VaultKey = Material{
+	Item: NewItem("Vault Key", 0, 60, 3),
+	Key:  "VaultKey",
+}
+
+func (plr *Player) HasVaultKey() bool {
+	return plr.CountMaterial(VaultKey.Key) > 0
+}
+
+// Strong characters have better odds, heavy loads don't help
+func (plr *Player) BashChance() int {
+	chance := minBashChance + plr.Entity.Level*3 + plr.Weapon.EffectiveDamage()/2 - plr.Encumbrance().InitiativePenalty
+	if chance < minBashChance {
+		chance = minBashChance
+	}
+	if chance > maxBashChance {
+		chance = maxBashChance
+	}
+	return chance
+}
+
+// Tries to break a locked door open, a failed attempt hurts. Returns whether the door gave way and the damage taken
+func (plr *Player) BashDoor() (bool, int) {
+	broken := GetRNG().Intn(100) < plr.BashChance()
+	damage := 0
+	if !broken {
+		damage = plr.Entity.TakeDamage(plr.Entity.MaxHP / 10)
+	}
+	RefreshSeedState()
+	return broken, damage
+}
+
+func RollElite() bool {
+	return GetRNG().Intn(100) < eliteChance
+}
+
+// Elites are tougher and always carry a vault key
+func (enm *Enemy) MakeElite() {
+	enm.IsElite = true
+	enm.Entity.Name = "Elite " + enm.Entity.Name
+	enm.Entity.MaxHP = enm.Entity.MaxHP * 3 / 2
+	enm.Entity.HP = enm.Entity.MaxHP
+	enm.EnemyRace.BonusDamage += 5
+	enm.Entity.Initiative += 3
+	enm.Entity.defaultXP *= 2
+}
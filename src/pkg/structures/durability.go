@@ -0,0 +1,146 @@
+package structures
+
+// Stats go down as the item wears out, broken items give nothing
+func durabilityFactor(durability, maxDurability int) float64 {
+	switch {
+	case maxDurability <= 0: // No durability (eg. "None" armor)
+		return 1.0
+	case durability <= 0:
+		return 0.0
+	case durability*4 <= maxDurability:
+		return 0.5
+	case durability*2 <= maxDurability:
+		return 0.75
+	default:
+		return 1.0
+	}
+}
+
+func (w Weapon) IsBroken() bool {
+	return w.MaxDurability > 0 && w.Durability <= 0
+}
+
+func (w Weapon) EffectiveDamage() int {
+	return int(float64(w.Damage+w.ElementDamage()) * durabilityFactor(w.Durability, w.MaxDurability))
+}
+
+// Returns true if the weapon just broke
+func (w *Weapon) Wear(amount int) bool {
+	if w.MaxDurability <= 0 || w.Durability <= 0 {
+		return false
+	}
+	w.Durability -= amount
+	if w.Durability < 0 {
+		w.Durability = 0
+	}
+	return w.Durability == 0
+}
+
+func (a Armors) IsBroken() bool {
+	return a.MaxDurability > 0 && a.Durability <= 0
+}
+
+func (a Armors) EffectiveDefense() int {
+	return int(float64(a.Defense) * durabilityFactor(a.Durability, a.MaxDurability))
+}
+
+func (a *Armors) Wear(amount int) bool {
+	if a.MaxDurability <= 0 || a.Durability <= 0 {
+		return false
+	}
+	a.Durability -= amount
+	if a.Durability < 0 {
+		a.Durability = 0
+	}
+	return a.Durability == 0
+}
+
+// Wears every equipped armor piece, returns the pieces that just broke
+func (ent *Entity) WearArmor(amount int) []Armors {
+	broken := []Armors{}
+	for _, piece := range ent.EquippedArmor() {
+		if piece.Wear(amount) {
+			broken = append(broken, *piece)
+		}
+	}
+	return broken
+}
+
+func weaponRarity(w Weapon) int {
+	return rarityFromWeaponDamage(w.Damage) + TierIndex(w.Tier)
+}
+
+func armorRarity(a Armors) int {
+	return rarityFromArmorName(a.Name) + TierIndex(a.Tier)
+}
+
+// Each missing point costs half the item rarity in gold
+func repairCost(durability, maxDurability, rarity int) int {
+	missing := maxDurability - durability
+	if missing <= 0 {
+		return 0
+	}
+	cost := missing * rarity / 2
+	if cost < 1 {
+		cost = 1
+	}
+	return cost
+}
+
+func (plr *Player) RepairCost() int {
+	cost := repairCost(plr.Weapon.Durability, plr.Weapon.MaxDurability, weaponRarity(plr.Weapon))
+	for _, piece := range plr.Entity.EquippedArmor() {
+		cost += repairCost(piece.Durability, piece.MaxDurability, armorRarity(*piece))
+	}
+	return cost
+}
+
+// Repairs all the equipped gear, returns the gold spent
+func (cb *CraftingBlacksmith) RepairAll(player *Player) (int, bool) {
+	cost := player.RepairCost()
+	if cost == 0 || player.Money < cost {
+		return cost, false
+	}
+	player.Money -= cost
+	player.Weapon.Durability = player.Weapon.MaxDurability
+	for _, piece := range player.Entity.EquippedArmor() {
+		piece.Durability = piece.MaxDurability
+	}
+	return cost, true
+}
+
+// Gear from older saves gets the durability and category of its template
+func fillWeaponDurability(w *Weapon) {
+	if w.MaxDurability == 0 {
+		w.MaxDurability = AllWeapons[w.Name].MaxDurability
+		w.Durability = w.MaxDurability
+	}
+}
+
+func fillArmorDurability(a *Armors) {
+	if a.MaxDurability == 0 {
+		a.MaxDurability = GetArmorByType(a.Type, a.Name).MaxDurability
+		a.Durability = a.MaxDurability
+	}
+}
+
+func (plr *Player) migrateGear() {
+	fillWeaponCategory(&plr.Weapon)
+	fillWeaponDurability(&plr.Weapon)
+	plr.Weapon = weaponFromTemplate(plr.Weapon) // Equipped gear follows balance changes too
+	for _, piece := range plr.Entity.EquippedArmor() {
+		fillArmorDurability(piece)
+		*piece = armorFromTemplate(*piece)
+	}
+	for i, entry := range plr.Inventory {
+		switch e := entry.(type) {
+		case WeaponItem:
+			fillWeaponCategory(&e.Weapon)
+			fillWeaponDurability(&e.Weapon)
+			plr.Inventory[i] = e
+		case ArmorItem:
+			fillArmorDurability(&e.Armor)
+			plr.Inventory[i] = e
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package structures
+
+import "time"
+
+type Encumbrance struct {
+	Name              string
+	MaxPercent        int // Of MaxCarryWeight, the tier applies up to this load
+	InitiativePenalty int
+	QTESpeedup        int           // % faster QTE bar
+	MoveDelay         time.Duration // Minimum time between two steps
+}
+
+// Going over MaxCarryWeight is allowed up to the last tier, items are refused past it
+var EncumbranceTiers = []Encumbrance{
+	{Name: "Light", MaxPercent: 100},
+	{Name: "Burdened", MaxPercent: 125, InitiativePenalty: 3, QTESpeedup: 25, MoveDelay: 120 * time.Millisecond},
+	{Name: "Strained", MaxPercent: 150, InitiativePenalty: 6, QTESpeedup: 50, MoveDelay: 250 * time.Millisecond},
+}
+
+// Weight past which nothing more can be carried
+func (plr *Player) HardCarryWeight() int {
+	return plr.MaxCarryWeight * EncumbranceTiers[len(EncumbranceTiers)-1].MaxPercent / 100
+}
+
+func (plr *Player) Encumbrance() Encumbrance {
+	weight := plr.CurrentCarryWeight()
+	for _, tier := range EncumbranceTiers {
+		if weight*100 <= plr.MaxCarryWeight*tier.MaxPercent {
+			return tier
+		}
+	}
+	return EncumbranceTiers[len(EncumbranceTiers)-1]
+}
+
+// Tick of the QTE bar, a heavy load makes it run faster
+func (plr *Player) QTESpeed(base time.Duration) time.Duration {
+	return base * 100 / time.Duration(100+plr.Encumbrance().QTESpeedup)
+}
+
+// Items that didn't fit in the bag, the game drops them on the floor next to the player
+func (plr *Player) DropOnFloor(entries []InventoryEntry) {
+	plr.Dropped = append(plr.Dropped, entries...)
+}
+
+// Empties the items waiting to be dropped
+func (plr *Player) TakeDropped() Inventory {
+	dropped := plr.Dropped
+	plr.Dropped = nil
+	return dropped
+}
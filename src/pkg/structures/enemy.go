@@ -1,21 +1,38 @@
 package structures
 
-import "math/rand"
+import (
+	"fmt"
+	"math/rand"
+)
 
 type Enemy struct {
 	Entity
 	Weapon
 	EnemyRace
-	IsBoss bool
-	Mana   int
-	Spells []Spell
+	IsBoss  bool
+	IsElite bool
+	Depth   int // How deep the enemy was met, used for loot
+	Mana    int
+	Spells  []Spell
 }
 
 func (enm *Enemy) InflictDamage(Action string, attackedEntity *Entity, spellUsed Spell, multi float64) (int, int) {
+	if strength, ok := enm.Entity.EffectModifier("Strength"); ok {
+		multi *= strength
+	}
 	switch Action {
 	case "Melee":
-		rawDamage := int(float64(enm.EnemyRace.BonusDamage+enm.Weapon.Damage) * multi)
-		actualDamage := attackedEntity.TakeDamage(rawDamage)
+		hits, hitMultiplier := enm.Weapon.MeleeHits()
+		rawDamage, actualDamage := 0, 0
+		for i := 0; i < hits && attackedEntity.Alive; i++ {
+			hitDamage := int(float64(enm.EnemyRace.BonusDamage+enm.Weapon.EffectiveDamage()) * multi * hitMultiplier)
+			rawDamage += hitDamage
+			actualDamage += attackedEntity.TakeDamage(hitDamage)
+		}
+		enm.Entity.applyLifesteal(enm.Weapon.AffixTotal("Lifesteal"), actualDamage)
+		if attackedEntity.Alive && enm.Weapon.rollBleed(attackedEntity) {
+			fmt.Printf("%s is bleeding!\n", attackedEntity.Name)
+		}
 		return rawDamage, actualDamage
 	case "Spell":
 		if spellUsed.Cost <= enm.Mana {
@@ -26,7 +43,7 @@ func (enm *Enemy) InflictDamage(Action string, attackedEntity *Entity, spellUsed
 			return rawDamage, actualDamage
 		}
 	case "HeavySlam":
-		base := enm.EnemyRace.BonusDamage + enm.Weapon.Damage
+		base := enm.EnemyRace.BonusDamage + enm.Weapon.EffectiveDamage()
 		rawDamage := int(float64(base) * 1.8 * multi)
 		actualDamage := attackedEntity.TakeDamage(rawDamage)
 		return rawDamage, actualDamage
@@ -101,12 +118,18 @@ func InitBoss(name string, race string) Enemy {
 func InitScaledEnemy(name string, race string, dungeonLevel int) Enemy {
 	enemy := InitEnemy(name, race)
 
-	// Calculate scaling factor based on dungeon level
-	// Level 0 = 1.0x (base difficulty)
-	// Level 1 = 1.2x
-	// Level 2 = 1.4x
-	// level 3 is a boss level
-	scalingFactor := 1.0 + (float64(dungeonLevel) * 0.2)
+	// Levels go negative going down the stairs, enemies scale with how deep they are
+	depth := dungeonLevel
+	if depth < 0 {
+		depth = -depth
+	}
+
+	// Calculate scaling factor based on depth
+	// Depth 0 = 1.0x (base difficulty)
+	// Depth 1 = 1.2x
+	// Depth 2 = 1.4x
+	// Depth 3 is a boss level
+	scalingFactor := 1.0 + (float64(depth) * 0.2)
 
 	baseHP := 100 + enemy.EnemyRace.BonusHP
 	scaledHP := int(float64(baseHP) * scalingFactor)
@@ -125,24 +148,24 @@ func InitScaledEnemy(name string, race string, dungeonLevel int) Enemy {
 	}
 
 	// Set level for display/identification
-	enemy.Entity.Level = dungeonLevel
+	enemy.Entity.Level = depth
 
-	if dungeonLevel > 0 {
+	if depth > 0 {
 		// Higher level enemies get better armor
-		armorScaling := 1.0 + (float64(dungeonLevel) * 0.2)
+		armorScaling := 1.0 + (float64(depth) * 0.2)
 		enemy.Entity.Helmet.Defense = int(float64(enemy.Entity.Helmet.Defense) * armorScaling)
 		enemy.Entity.Chestplate.Defense = int(float64(enemy.Entity.Chestplate.Defense) * armorScaling)
 		enemy.Entity.Boots.Defense = int(float64(enemy.Entity.Boots.Defense) * armorScaling)
 	}
 
 	// Give higher level enemies better weapons occasionally
-	if dungeonLevel >= 2 {
+	if depth >= 2 {
 		weapons := []string{"Axe", "DoubleAxes", "Spear"}
 		weaponIndex := GetRNG().Intn(len(weapons))
 		selectedWeapon := AllWeapons[weapons[weaponIndex]]
 
 		// Scale weapon damage
-		weaponScaling := 1.0 + (float64(dungeonLevel) * 0.15)
+		weaponScaling := 1.0 + (float64(depth) * 0.15)
 		selectedWeapon.Damage = int(float64(selectedWeapon.Damage) * weaponScaling)
 		enemy.Weapon = selectedWeapon
 	}
@@ -0,0 +1,15 @@
+package structures
+
+import "testing"
+
+func TestScaledEnemiesGetStrongerDeeper(t *testing.T) {
+	shallow := InitScaledEnemy("Gruk", "Orc", -1)
+	deep := InitScaledEnemy("Gruk", "Orc", -6)
+	if deep.MaxHP <= shallow.MaxHP || deep.EnemyRace.BonusDamage < shallow.EnemyRace.BonusDamage {
+		t.Fatalf("depth 6 orc has %d HP and %d damage, depth 1 has %d HP and %d damage",
+			deep.MaxHP, deep.EnemyRace.BonusDamage, shallow.MaxHP, shallow.EnemyRace.BonusDamage)
+	}
+	if mimic := InitMimic(6); mimic.MaxHP != InitScaledEnemy("Mimic", "Mimic", -6).MaxHP {
+		t.Fatalf("depth 6 mimic has %d HP, a depth 6 mob of its race %d", mimic.MaxHP, InitScaledEnemy("Mimic", "Mimic", -6).MaxHP)
+	}
+}
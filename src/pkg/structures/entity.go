@@ -18,12 +18,76 @@ type Entity struct {
 	Helmet     Armors
 	Chestplate Armors
 	Boots      Armors
+	Ring1      Armors
+	Ring2      Armors
+	Amulet     Armors
+	Shield     Armors
 	defaultXP  int
 	Effects    []Effect
 }
 
+// All the equipped armor slots, including jewelry and the off-hand
+func (ent *Entity) EquippedArmor() []*Armors {
+	return []*Armors{&ent.Helmet, &ent.Chestplate, &ent.Boots, &ent.Ring1, &ent.Ring2, &ent.Amulet, &ent.Shield}
+}
+
+// Slot the piece goes in, the first free ring slot is used for rings
+func (ent *Entity) armorSlot(armor Armors) *Armors {
+	switch armor.Type {
+	case "Helmet":
+		return &ent.Helmet
+	case "Chestplate":
+		return &ent.Chestplate
+	case "Boots":
+		return &ent.Boots
+	case "Ring":
+		if ent.Ring2.Cursed {
+			return &ent.Ring1
+		}
+		if ent.Ring1.Cursed {
+			return &ent.Ring2
+		}
+		if ent.Ring1.Name == "None" || ent.Ring1.Name == "" || (ent.Ring2.Name != "None" && ent.Ring2.Name != "") {
+			return &ent.Ring1
+		}
+		return &ent.Ring2
+	case "Amulet":
+		return &ent.Amulet
+	case "Shield":
+		return &ent.Shield
+	}
+	return nil
+}
+
+// Equips the piece in the slot matching its type, returns false if a cursed piece is stuck in it
+func (ent *Entity) EquipArmor(armor Armors) bool {
+	slot := ent.armorSlot(armor)
+	if slot == nil || slot.Cursed {
+		return false
+	}
+	armor.Unidentified = false // Wearing it reveals what it does
+	*slot = armor
+	return true
+}
+
+// Shield block chance, a broken shield can't block
+func (ent *Entity) BlockChance() int {
+	if ent.Shield.IsBroken() {
+		return 0
+	}
+	return ent.Shield.BlockChance
+}
+
+func (ent *Entity) TotalDefense() int {
+	defense := GetSetBonusDefense(*ent) + ent.ArmorAffixTotal("Defense")
+	for _, piece := range ent.EquippedArmor() {
+		defense += piece.EffectiveDefense()
+	}
+	return defense
+}
+
 func (ent *Entity) TakeDamage(damage int) int {
-	defense := ent.Helmet.Defense + ent.Chestplate.Defense + ent.Boots.Defense + GetSetBonusDefense(*ent)
+	defense := ent.TotalDefense()
 
 	defensePercent := float64(defense) * 2.0
 	if defensePercent > 85 {
@@ -31,6 +95,9 @@ func (ent *Entity) TakeDamage(damage int) int {
 	}
 
 	actualDamage := int(float64(damage) * (100.0 - defensePercent) / 100.0)
+	if resistance, ok := ent.EffectModifier("Resistance"); ok {
+		actualDamage = int(float64(actualDamage) * resistance)
+	}
 
 	if damage > 0 && actualDamage == 0 {
 		actualDamage = 1
@@ -45,14 +112,66 @@ func (ent *Entity) TakeDamage(damage int) int {
 	return actualDamage
 }
 
+func (ent *Entity) EffectModifier(name string) (float64, bool) {
+	for _, eff := range ent.Effects {
+		if eff.Name == name {
+			return eff.Modifier, true
+		}
+	}
+	return 0, false
+}
+
+// Adds an effect, refreshing it instead of stacking if already active
+func (ent *Entity) AddEffect(effect Effect) {
+	for i, eff := range ent.Effects {
+		if eff.Name == effect.Name {
+			ent.Effects[i] = effect
+			return
+		}
+	}
+	ent.Effects = append(ent.Effects, effect)
+}
+
 func ProcessEffects(entity *Entity) {
+	for _, msg := range TickEffects(entity) {
+		fmt.Println(msg)
+	}
+}
+
+// Effects that hurt every turn, the others only wear off in fights
+var damageOverTime = map[string]bool{"Burn": true, "Bleed": true, "Poisoned": true}
+
+// One fight turn of the effects, returns what they did
+func TickEffects(entity *Entity) []string {
+	return tickEffects(entity, false)
+}
+
+// One step on the map, ailments keep hurting while buffs last until the next fight
+func TickAilments(entity *Entity) []string {
+	return tickEffects(entity, true)
+}
+
+func tickEffects(entity *Entity, ailmentsOnly bool) []string {
+	messages := []string{}
 	remainingEffects := []Effect{}
 	for _, eff := range entity.Effects {
+		if ailmentsOnly && !damageOverTime[eff.Name] {
+			remainingEffects = append(remainingEffects, eff)
+			continue
+		}
 		switch eff.Name {
 		case "Burn":
 			burnDmg := int(float64(entity.MaxHP) * eff.Modifier)
-			entity.TakeDamage(burnDmg)
-			fmt.Printf("%s takes %d burn damage!\n", entity.Name, burnDmg)
+			dealt := entity.TakeDamage(burnDmg)
+			messages = append(messages, fmt.Sprintf("%s takes %d burn damage!", entity.Name, dealt))
+		case "Bleed":
+			bleedDmg := int(float64(entity.MaxHP) * eff.Modifier)
+			dealt := entity.TakeDamage(bleedDmg)
+			messages = append(messages, fmt.Sprintf("%s takes %d bleed damage!", entity.Name, dealt))
+		case "Poisoned":
+			poisonDmg := int(float64(entity.MaxHP) * eff.Modifier)
+			dealt := entity.TakeDamage(poisonDmg)
+			messages = append(messages, fmt.Sprintf("%s takes %d poison damage!", entity.Name, dealt))
 		}
 		eff.Duration--
 		if eff.Duration > 0 {
@@ -60,4 +179,5 @@ func ProcessEffects(entity *Entity) {
 		}
 	}
 	entity.Effects = remainingEffects
+	return messages
 }
@@ -0,0 +1,24 @@
+package structures
+
+import "testing"
+
+func TestMapStepsKeepBuffsForTheFight(t *testing.T) {
+	entity := Entity{HP: 100, MaxHP: 100, Name: "Hero", Alive: true}
+	entity.AddEffect(Effect{Name: "Strength", Duration: 6, Modifier: 1.3})
+	entity.AddEffect(TrapPoison())
+
+	for i := 0; i < 10; i++ {
+		TickAilments(&entity)
+	}
+	if len(entity.Effects) != 1 || entity.Effects[0].Name != "Strength" || entity.Effects[0].Duration != 6 {
+		t.Fatalf("effects after 10 steps = %+v, want the untouched Strength buff only", entity.Effects)
+	}
+	if entity.HP >= 100 {
+		t.Fatalf("HP after walking poisoned = %d, want less than 100", entity.HP)
+	}
+
+	TickEffects(&entity)
+	if entity.Effects[0].Duration != 5 {
+		t.Fatalf("Strength duration after a fight turn = %d, want 5", entity.Effects[0].Duration)
+	}
+}
@@ -0,0 +1,177 @@
+package structures
+
+import "main/pkg/save"
+
+const (
+	unidentifiedMinDepth  = 3
+	unidentifiedChance    = 10 // Per depth level from unidentifiedMinDepth
+	maxUnidentifiedChance = 60
+	cursedChance          = 30 // Of the unidentified drops
+	curseAffixName        = "Cursed"
+
+	IdentifyPrice = 60  // Merchant service, per item
+	UncursePrice  = 150 // Merchant service, per item
+)
+
+// Deeper gear drops hide their stats, some of them are cursed
+func rollUnidentified(depth int) (bool, bool) {
+	if depth < unidentifiedMinDepth {
+		return false, false
+	}
+	chance := unidentifiedChance * (depth - unidentifiedMinDepth + 1)
+	if chance > maxUnidentifiedChance {
+		chance = maxUnidentifiedChance
+	}
+	rng := GetRNG()
+	if rng.Intn(100) >= chance {
+		return false, false
+	}
+	return true, rng.Intn(100) < cursedChance
+}
+
+// Curses weigh on initiative for weapons and on defense for armors, more so deeper
+func curseAffix(forWeapon bool, depth int) Affix {
+	stat := "Defense"
+	if forWeapon {
+		stat = "Initiative"
+	}
+	return Affix{Name: curseAffixName, Prefix: true, Stat: stat, Value: -(3 + depth/2)}
+}
+
+func hideWeapon(w Weapon, depth int) Weapon {
+	unidentified, cursed := rollUnidentified(depth)
+	w.Unidentified = unidentified
+	if cursed {
+		w.Cursed = true
+		w.Affixes = append(w.Affixes, curseAffix(true, depth))
+	}
+	return w
+}
+
+func hideArmor(a Armors, depth int) Armors {
+	unidentified, cursed := rollUnidentified(depth)
+	a.Unidentified = unidentified
+	if cursed {
+		a.Cursed = true
+		a.Affixes = append(a.Affixes, curseAffix(false, depth))
+	}
+	return a
+}
+
+func withoutCurse(affixes []Affix) []Affix {
+	kept := []Affix{}
+	for _, a := range affixes {
+		if a.Name != curseAffixName {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+func IsUnidentified(entry InventoryEntry) bool {
+	switch e := entry.(type) {
+	case WeaponItem:
+		return e.Weapon.Unidentified
+	case ArmorItem:
+		return e.Armor.Unidentified
+	}
+	return false
+}
+
+// Reveals the stats of the entry, the instance id is kept
+func identify(entry InventoryEntry) InventoryEntry {
+	switch e := entry.(type) {
+	case WeaponItem:
+		e.Weapon.Unidentified = false
+		e.Item.Name = e.Weapon.DisplayName()
+		return e
+	case ArmorItem:
+		e.Armor.Unidentified = false
+		e.Item.Name = e.Armor.DisplayName()
+		return e
+	}
+	return entry
+}
+
+// Identifies the first unidentified item of the inventory, returns its real name
+func (plr *Player) IdentifyNext() (string, bool) {
+	for i, entry := range plr.Inventory {
+		if IsUnidentified(entry) {
+			plr.Inventory[i] = identify(entry)
+			return plr.Inventory[i].GetItem().Name, true
+		}
+	}
+	return "", false
+}
+
+// Lifts the first curse found, equipped gear first, returns false if nothing is cursed
+func (plr *Player) LiftCurse() bool {
+	if plr.Weapon.Cursed {
+		plr.Weapon.Cursed = false
+		plr.Weapon.Affixes = withoutCurse(plr.Weapon.Affixes)
+		return true
+	}
+	for _, piece := range plr.Entity.EquippedArmor() {
+		if piece.Cursed {
+			piece.Cursed = false
+			piece.Affixes = withoutCurse(piece.Affixes)
+			return true
+		}
+	}
+	for i, entry := range plr.Inventory {
+		switch e := entry.(type) {
+		case WeaponItem:
+			if e.Weapon.Cursed && !e.Weapon.Unidentified {
+				e.Weapon.Cursed = false
+				e.Weapon.Affixes = withoutCurse(e.Weapon.Affixes)
+				e.Item.Name = e.Weapon.DisplayName()
+				plr.Inventory[i] = e
+				return true
+			}
+		case ArmorItem:
+			if e.Armor.Cursed && !e.Armor.Unidentified {
+				e.Armor.Cursed = false
+				e.Armor.Affixes = withoutCurse(e.Armor.Affixes)
+				e.Item.Name = e.Armor.DisplayName()
+				plr.Inventory[i] = e
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Equipping reveals the weapon, a cursed weapon in hand can't be swapped
+func (plr *Player) EquipWeapon(w Weapon) bool {
+	if plr.Weapon.Cursed {
+		return false
+	}
+	w.Unidentified = false
+	plr.Weapon = w
+	return true
+}
+
+// Merchant service, identifies as many items as the player can pay for
+func (m *Merchant) Identify(plr *Player) int {
+	count := 0
+	for plr.Money >= IdentifyPrice {
+		if _, ok := plr.IdentifyNext(); !ok {
+			break
+		}
+		plr.Money -= IdentifyPrice
+		count++
+	}
+	save.SaveAny("player", plr)
+	return count
+}
+
+// Merchant service, lifts as many curses as the player can pay for
+func (m *Merchant) RemoveCurses(plr *Player) int {
+	count := 0
+	for plr.Money >= UncursePrice && plr.LiftCurse() {
+		plr.Money -= UncursePrice
+		count++
+	}
+	save.SaveAny("player", plr)
+	return count
+}
@@ -1,6 +1,10 @@
 package structures
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
 
 type Inventory []InventoryEntry
 
@@ -17,52 +21,141 @@ func (inv *Inventory) UnmarshalJSON(data []byte) error { // Required for json.Un
 			return err
 		}
 
+		if m["template"] != nil {
+			var saved savedEntry
+			if err := json.Unmarshal(b, &saved); err != nil {
+				return err
+			}
+			SyncItemCounter(saved.Id)
+			if entry, ok := fromSavedEntry(saved); ok {
+				entries = append(entries, entry)
+			}
+			continue
+		}
+
+		var legacy InventoryEntry
 		switch {
 		case m["Key"] != nil:
 			var mat Material
 			if err := json.Unmarshal(b, &mat); err != nil {
 				return err
 			}
-			entries = append(entries, mat)
+			legacy = mat
 		case m["Spell"] != nil:
 			var sb Spellbooks
 			if err := json.Unmarshal(b, &sb); err != nil {
 				return err
 			}
-			entries = append(entries, sb)
+			legacy = sb
 		case m["Armor"] != nil:
 			var ai ArmorItem
 			if err := json.Unmarshal(b, &ai); err != nil {
 				return err
 			}
-			entries = append(entries, ai)
+			legacy = ai
 		case m["Weapon"] != nil:
 			var wi WeaponItem
 			if err := json.Unmarshal(b, &wi); err != nil {
 				return err
 			}
-			entries = append(entries, wi)
+			legacy = wi
 		case m["Size"] != nil && m["Type"] != nil:
 			var p Potion
 			if err := json.Unmarshal(b, &p); err != nil {
 				return err
 			}
-			entries = append(entries, p)
+			legacy = p
+		case m["RecipeKey"] != nil:
+			var rs RecipeScroll
+			if err := json.Unmarshal(b, &rs); err != nil {
+				return err
+			}
+			legacy = rs
 		case m["CapacityIncrease"] != nil:
 			var bi BackpackItem
 			if err := json.Unmarshal(b, &bi); err != nil {
 				return err
 			}
-			entries = append(entries, bi)
+			legacy = bi
 		default:
 			var it Item
 			if err := json.Unmarshal(b, &it); err != nil {
 				return err
 			}
-			entries = append(entries, it)
+			legacy = it
 		}
+		entries = append(entries, fromLegacyEntry(legacy))
 	}
 
 	*inv = entries
 	return nil
 }
+
+var InventoryCategories = []string{"All", "Materials", "Potions", "Gear", "Spellbooks", "Scrolls", "Backpacks"}
+
+// "Default" keeps the order items were picked up in
+var InventorySorts = []string{"Default", "Name", "Rarity", "Weight", "Value"}
+
+func InventoryCategory(entry InventoryEntry) string {
+	switch entry.(type) {
+	case Material:
+		return "Materials"
+	case Potion:
+		return "Potions"
+	case WeaponItem, ArmorItem:
+		return "Gear"
+	case Spellbooks:
+		return "Spellbooks"
+	case RecipeScroll, Scroll:
+		return "Scrolls"
+	case BackpackItem:
+		return "Backpacks"
+	}
+	return "Other"
+}
+
+func NextInventorySort(current string) string {
+	for i, s := range InventorySorts {
+		if s == current {
+			return InventorySorts[(i+1)%len(InventorySorts)]
+		}
+	}
+	return InventorySorts[1]
+}
+
+// Gear tier first, then the base item rarity
+func entryRarity(entry InventoryEntry) int {
+	return TierIndex(ItemTier(entry))*10 + entry.GetItem().Rarity
+}
+
+// Indices of the entries in the category whose name contains the search, in the player's sort order
+func (plr *Player) ListInventory(category, search string) []int {
+	search = strings.ToLower(search)
+	indices := []int{}
+	for i, entry := range plr.Inventory {
+		if category != "All" && InventoryCategory(entry) != category {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(entry.GetItem().Name), search) {
+			continue
+		}
+		indices = append(indices, i)
+	}
+
+	inv := plr.Inventory
+	sort.SliceStable(indices, func(a, b int) bool {
+		ia, ib := inv[indices[a]], inv[indices[b]]
+		switch plr.InventorySort {
+		case "Name":
+			return strings.ToLower(ia.GetItem().Name) < strings.ToLower(ib.GetItem().Name)
+		case "Rarity":
+			return entryRarity(ia) > entryRarity(ib)
+		case "Weight":
+			return ia.GetItem().Weight < ib.GetItem().Weight
+		case "Value":
+			return ia.GetItem().Price > ib.GetItem().Price
+		}
+		return false
+	})
+	return indices
+}
@@ -1,11 +1,12 @@
 package structures
 
 type Item struct {
-	Name   string
-	Id     int
-	Weight int
-	Price  int
-	Rarity int
+	Name        string
+	Id          int
+	TemplateKey string // Key in ItemTemplates
+	Weight      int
+	Price       int
+	Rarity      int
 }
 
 type InventoryEntry interface {
@@ -14,14 +15,9 @@ type InventoryEntry interface {
 
 func (it Item) GetItem() Item { return it }
 
-func newItemID() int {
-	return 1 + GetRNG().Intn(2000000000)
-}
-
 func NewItem(name string, weight int, price int, rarity int) Item {
 	return Item{
 		Name:   name,
-		Id:     newItemID(),
 		Weight: weight,
 		Price:  price,
 		Rarity: rarity,
@@ -48,25 +44,26 @@ var (
 	GoblinEar    = NewMaterial("GoblinEar", "Goblin Ear")
 	SkeletonBone = NewMaterial("SkeletonBone", "Skeleton Bone")
 	OrcTusk      = NewMaterial("OrcTusk", "Orc Tusk")
+
+	// Alchemy herbs
+	Moonleaf  = NewMaterial("Moonleaf", "Moonleaf")
+	Glowmoss  = NewMaterial("Glowmoss", "Glowmoss")
+	Bloodroot = NewMaterial("Bloodroot", "Bloodroot")
+	Ashcap    = NewMaterial("Ashcap", "Ashcap")
 )
 
 var AllMaterials = map[string]Material{
 	"GoblinEar":    GoblinEar,
 	"SkeletonBone": SkeletonBone,
 	"OrcTusk":      OrcTusk,
-}
-
-func GenerateLootFromEnemy(r EnemyRace) InventoryEntry {
-	switch r.Name {
-	case "Goblin":
-		return GoblinEar
-	case "Skeleton":
-		return SkeletonBone
-	case "Orc":
-		return OrcTusk
-	default:
-		return GoblinEar
-	}
+	"Moonleaf":     Moonleaf,
+	"Glowmoss":     Glowmoss,
+	"Bloodroot":    Bloodroot,
+	"Ashcap":       Ashcap,
+	"ChestKey":     ChestKey,
+	"VaultKey":     VaultKey,
+	"ScrapMetal":   ScrapMetal,
+	"ArcaneDust":   ArcaneDust,
 }
 
 type Potion struct {
@@ -78,10 +75,13 @@ type Potion struct {
 func (p Potion) GetItem() Item { return p.Item }
 
 func GetPotion(potionType string, size int, price int) Potion {
+	item := NewItem(potionType+" Potion", 1, price, 1)
+	item.Id = NewItemID()
+	item.TemplateKey = "potion:" + potionType
 	return Potion{
 		Size: size,
 		Type: potionType,
-		Item: NewItem(potionType+" Potion", 1, price, 1),
+		Item: item,
 	}
 }
 
@@ -101,12 +101,36 @@ var (
 		Type: "Heal",
 		Item: NewItem("Heal Potion", 1, 50, 1),
 	}
+	ManaPotion = Potion{
+		Size: 1,
+		Type: "Mana",
+		Item: NewItem("Mana Potion", 1, 60, 2),
+	}
+	Strength = Potion{
+		Size: 1,
+		Type: "Strength",
+		Item: NewItem("Strength Potion", 1, 120, 3),
+	}
+	Resistance = Potion{
+		Size: 1,
+		Type: "Resistance",
+		Item: NewItem("Resistance Potion", 1, 120, 3),
+	}
+	Haste = Potion{
+		Size: 1,
+		Type: "Haste",
+		Item: NewItem("Haste Potion", 1, 100, 3),
+	}
 )
 
 var AllPotions = map[string]Potion{
-	"Poison": Poison,
-	"Cure":   Cure,
-	"Heal":   Heal,
+	"Poison":     Poison,
+	"Cure":       Cure,
+	"Heal":       Heal,
+	"Mana":       ManaPotion,
+	"Strength":   Strength,
+	"Resistance": Resistance,
+	"Haste":      Haste,
 }
 
 type Spellbooks struct {
@@ -191,44 +215,21 @@ func GetRandomItemByRarity() InventoryEntry { // Chatgpt based ♥
 
 	var pool []weightedEntry
 
-	for _, p := range AllPotions {
-		pool = append(pool, weightedEntry{entry: p, weight: weightFromRarity(p.Item.Rarity)})
-	}
-
-	for _, sb := range AllSpellbooks {
-		pool = append(pool, weightedEntry{entry: sb, weight: weightFromRarity(sb.Item.Rarity)})
-	}
-
-	for _, m := range AllMaterials {
-		pool = append(pool, weightedEntry{entry: m, weight: weightFromRarity(m.Item.Rarity)})
-	}
-
-	for _, w := range AllWeapons {
-		if w.Name != "Sword" { // Default weapon
-			wi := NewWeaponItem(w)
-			pool = append(pool, weightedEntry{entry: wi, weight: weightFromRarity(wi.Item.Rarity)})
+	for _, key := range TemplateKeys() {
+		entry := ItemTemplates[key]
+		switch e := entry.(type) {
+		case RecipeScroll: // Only found in the dungeon
+			continue
+		case WeaponItem:
+			if e.Weapon.Name == "Sword" { // Default weapon
+				continue
+			}
 		}
-	}
-
-	for _, a := range AllHelmets {
-		ai := NewArmorItem(a)
-		pool = append(pool, weightedEntry{entry: ai, weight: weightFromRarity(ai.Item.Rarity)})
-	}
-	for _, a := range AllChestplates {
-		ai := NewArmorItem(a)
-		pool = append(pool, weightedEntry{entry: ai, weight: weightFromRarity(ai.Item.Rarity)})
-	}
-	for _, a := range AllBoots {
-		ai := NewArmorItem(a)
-		pool = append(pool, weightedEntry{entry: ai, weight: weightFromRarity(ai.Item.Rarity)})
-	}
-
-	for _, b := range AllBackpacks {
-		pool = append(pool, weightedEntry{entry: b, weight: weightFromRarity(b.Item.Rarity)})
+		pool = append(pool, weightedEntry{entry: entry, weight: weightFromRarity(entry.GetItem().Rarity)})
 	}
 
 	if len(pool) == 0 {
-		return Heal
+		return NewInstance(AllPotions["Heal"])
 	}
 
 	total := 0
@@ -236,7 +237,7 @@ func GetRandomItemByRarity() InventoryEntry { // Chatgpt based ♥
 		total += we.weight
 	}
 	if total <= 0 {
-		return Heal
+		return NewInstance(AllPotions["Heal"])
 	}
 
 	r := GetRNG().Intn(total)
@@ -245,11 +246,22 @@ func GetRandomItemByRarity() InventoryEntry { // Chatgpt based ♥
 		cumulative += we.weight
 		if r < cumulative {
 			RefreshSeedState()
-			return we.entry
+			return rollItemAffixes(we.entry)
 		}
 	}
 	RefreshSeedState()
-	return pool[len(pool)-1].entry
+	return rollItemAffixes(pool[len(pool)-1].entry)
+}
+
+// Creates an instance of the template, weapons and armors get their tier and affixes rolled
+func rollItemAffixes(entry InventoryEntry) InventoryEntry {
+	switch e := entry.(type) {
+	case WeaponItem:
+		return NewWeaponItem(RollWeapon(e.Weapon))
+	case ArmorItem:
+		return NewArmorItem(RollArmor(e.Armor))
+	}
+	return NewInstance(entry)
 }
 
 type WeaponItem struct {
@@ -272,9 +284,17 @@ func rarityFromWeaponDamage(damage int) int {
 	}
 }
 
+// Higher tiers sell for more, +50% per tier above Common
+func priceWithTier(price int, tier string) int {
+	return price + price*TierIndex(tier)/2
+}
+
 func NewWeaponItem(weapon Weapon) WeaponItem {
+	item := NewItem(weapon.DisplayName(), 0, priceWithTier(weapon.Damage*10, weapon.Tier), rarityFromWeaponDamage(weapon.Damage)+TierIndex(weapon.Tier))
+	item.Id = NewItemID()
+	item.TemplateKey = "weapon:" + weapon.Name
 	return WeaponItem{
-		Item:   NewItem(weapon.Name, 0, weapon.Damage*10, rarityFromWeaponDamage(weapon.Damage)),
+		Item:   item,
 		Weapon: weapon,
 	}
 }
@@ -290,17 +310,48 @@ func rarityFromArmorName(name string) int {
 	switch name {
 	case "StormBringer":
 		return 6
-	case "SunBreaker":
+	case "SunBreaker", "Ruby", "Guardian", "Tower":
 		return 4
+	case "Sage":
+		return 5
+	case "Sapphire", "Emerald", "Kite":
+		return 3
 	default:
 		return 2
 	}
 }
 
+func armorBasePrice(armor Armors) int {
+	switch armor.Type {
+	case "Ring", "Amulet":
+		return rarityFromArmorName(armor.Name) * 60
+	case "Shield":
+		return armor.Defense*10 + armor.BlockChance*2
+	default:
+		return armor.Defense * 10
+	}
+}
+
 func NewArmorItem(armor Armors) ArmorItem {
-	label := armor.Type + " " + armor.Name
+	item := NewItem(armor.DisplayName(), 0, priceWithTier(armorBasePrice(armor), armor.Tier), rarityFromArmorName(armor.Name)+TierIndex(armor.Tier))
+	item.Id = NewItemID()
+	item.TemplateKey = armorTemplateKey(armor)
 	return ArmorItem{
-		Item:  NewItem(label, 0, armor.Defense*10, rarityFromArmorName(armor.Name)),
+		Item:  item,
 		Armor: armor,
 	}
 }
+
+// Tier of weapon and armor items, other items and unidentified gear have none
+func ItemTier(entry InventoryEntry) string {
+	if IsUnidentified(entry) {
+		return ""
+	}
+	switch e := entry.(type) {
+	case WeaponItem:
+		return e.Weapon.Tier
+	case ArmorItem:
+		return e.Armor.Tier
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package structures
+
+import "sort"
+
+type LootEntry struct {
+	Kind       string // material | herb | potion | spellbook | scroll | gear | accessory
+	Key        string // Key in the matching All* map, unused for herb/gear/accessory
+	Weight     int
+	MinDepth   int // Not dropped above this depth
+	DepthBonus int // Weight added per depth level, makes rare drops more common deeper
+}
+
+// The race material is always dropped, these tables are rolled on top of it
+var RaceLootTables = map[string][]LootEntry{
+	"Goblin": {
+		{Kind: "material", Key: "GoblinEar", Weight: 40},
+		{Kind: "herb", Weight: 30},
+		{Kind: "potion", Key: "Heal", Weight: 15},
+		{Kind: "potion", Key: "Haste", Weight: 4, MinDepth: 2, DepthBonus: 1},
+		{Kind: "scroll", Key: "Teleport", Weight: 4, MinDepth: 1},
+		{Kind: "accessory", Weight: 3, MinDepth: 1, DepthBonus: 1},
+		{Kind: "spellbook", Key: "SpellBookPoisonFlask", Weight: 1, MinDepth: 4, DepthBonus: 1},
+	},
+	"Skeleton": {
+		{Kind: "material", Key: "SkeletonBone", Weight: 40},
+		{Kind: "herb", Weight: 20},
+		{Kind: "potion", Key: "Mana", Weight: 15},
+		{Kind: "potion", Key: "Resistance", Weight: 4, MinDepth: 2, DepthBonus: 1},
+		{Kind: "scroll", Key: "Identify", Weight: 4, MinDepth: 1},
+		{Kind: "scroll", Key: "MagicMapping", Weight: 2, MinDepth: 2},
+		{Kind: "gear", Weight: 4, MinDepth: 1, DepthBonus: 1},
+		{Kind: "spellbook", Key: "SpellBookIceBlast", Weight: 1, MinDepth: 4, DepthBonus: 1},
+	},
+	"Orc": {
+		{Kind: "material", Key: "OrcTusk", Weight: 40},
+		{Kind: "herb", Weight: 20},
+		{Kind: "potion", Key: "Heal", Weight: 10},
+		{Kind: "potion", Key: "Strength", Weight: 4, MinDepth: 2, DepthBonus: 1},
+		{Kind: "scroll", Key: "TownPortal", Weight: 3, MinDepth: 2},
+		{Kind: "gear", Weight: 6, MinDepth: 1, DepthBonus: 2},
+		{Kind: "spellbook", Key: "SpellBookFireball", Weight: 1, MinDepth: 4, DepthBonus: 1},
+	},
+	"Mimic": {
+		{Kind: "gear", Weight: 20, DepthBonus: 2},
+		{Kind: "accessory", Weight: 10, DepthBonus: 1},
+		{Kind: "potion", Key: "Heal", Weight: 10},
+	},
+}
+
+// Bosses always drop a piece of gear of at least this tier
+const bossGearMinTier = "Rare"
+
+func rollLootEntry(table []LootEntry, depth int) (LootEntry, bool) {
+	total := 0
+	for _, e := range table {
+		if depth >= e.MinDepth {
+			total += e.Weight + e.DepthBonus*depth
+		}
+	}
+	if total <= 0 {
+		return LootEntry{}, false
+	}
+	r := GetRNG().Intn(total)
+	cumulative := 0
+	for _, e := range table {
+		if depth < e.MinDepth {
+			continue
+		}
+		cumulative += e.Weight + e.DepthBonus*depth
+		if r < cumulative {
+			return e, true
+		}
+	}
+	return LootEntry{}, false
+}
+
+func lootFromEntry(e LootEntry, depth int) InventoryEntry {
+	rng := GetRNG()
+	switch e.Kind {
+	case "material":
+		return NewInstance(AllMaterials[e.Key])
+	case "herb":
+		return NewInstance(AllMaterials[herbKeys[rng.Intn(len(herbKeys))]])
+	case "potion":
+		return NewInstance(AllPotions[e.Key])
+	case "spellbook":
+		return NewInstance(AllSpellbooks[e.Key])
+	case "scroll":
+		return NewInstance(AllScrolls[e.Key])
+	case "accessory":
+		return NewArmorItem(hideArmor(RollArmor(randomGearTemplate(true).Armor), depth))
+	default:
+		g := randomGearTemplate(false)
+		if g.IsWeapon {
+			return NewWeaponItem(hideWeapon(RollWeapon(g.Weapon), depth))
+		}
+		return NewArmorItem(hideArmor(RollArmor(g.Armor), depth))
+	}
+}
+
+// Picks a weapon or armor template, sorted since map order is random and drops must follow the seed
+func randomGearTemplate(accessoriesOnly bool) gearTemplate {
+	pool := []gearTemplate{}
+	slots := []map[string]Armors{AllRings, AllAmulets, AllShields}
+	if !accessoriesOnly {
+		slots = append(slots, AllHelmets, AllChestplates, AllBoots)
+		for _, w := range AllWeapons {
+			pool = append(pool, gearTemplate{Weapon: w, IsWeapon: true})
+		}
+	}
+	for _, slot := range slots {
+		for _, a := range slot {
+			pool = append(pool, gearTemplate{Armor: a})
+		}
+	}
+	sort.Slice(pool, func(i, j int) bool { return pool[i].sortKey() < pool[j].sortKey() })
+	return pool[GetRNG().Intn(len(pool))]
+}
+
+type gearTemplate struct {
+	Weapon   Weapon
+	Armor    Armors
+	IsWeapon bool
+}
+
+func (g gearTemplate) sortKey() string {
+	if g.IsWeapon {
+		return "Weapon" + g.Weapon.Name
+	}
+	return g.Armor.Type + g.Armor.Name
+}
+
+func rollBossGear() InventoryEntry {
+	g := randomGearTemplate(false)
+	if g.IsWeapon {
+		w := RollWeapon(g.Weapon)
+		if TierIndex(w.Tier) < TierIndex(bossGearMinTier) {
+			w.Tier = bossGearMinTier
+			w.Affixes = rollAffixes(w.Tier, true)
+		}
+		return NewWeaponItem(w)
+	}
+	a := RollArmor(g.Armor)
+	if TierIndex(a.Tier) < TierIndex(bossGearMinTier) {
+		a.Tier = bossGearMinTier
+		a.Affixes = rollAffixes(a.Tier, false)
+	}
+	return NewArmorItem(a)
+}
+
+// Rolls everything an enemy drops, deeper enemies roll more times
+func GenerateLoot(enemy *Enemy) []InventoryEntry {
+	drops := []InventoryEntry{}
+	if material, ok := AllMaterials[enemy.EnemyRace.Drop]; ok {
+		drops = append(drops, NewInstance(material))
+	}
+	rolls := 1 + enemy.Depth/3
+	if rolls > 3 {
+		rolls = 3
+	}
+	if enemy.IsBoss {
+		rolls += 2
+		drops = append(drops, rollBossGear())
+	}
+	if enemy.IsElite {
+		rolls++
+		drops = append(drops, NewInstance(VaultKey))
+	}
+	table := RaceLootTables[enemy.EnemyRace.Name]
+	for i := 0; i < rolls; i++ {
+		if e, ok := rollLootEntry(table, enemy.Depth); ok {
+			drops = append(drops, lootFromEntry(e, enemy.Depth))
+		}
+	}
+	RefreshSeedState()
+	return drops
+}
+
+func RollGold(depth int, isBoss bool) int {
+	gold := GetRNG().Intn(30) + 1 + depth*5
+	if isBoss {
+		gold = gold*3 + 50
+	}
+	return gold
+}
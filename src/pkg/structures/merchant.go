@@ -38,7 +38,7 @@ func (m *Merchant) BuyItem(player *Player, entry InventoryEntry) bool {
 	player.Money -= entry.GetItem().Price
 	for _, item := range m.Inventory {
 		if item.GetItem().Id == entry.GetItem().Id {
-			player.AddItem(item)
+			player.AddItem(NewInstance(item)) // The stock may be older than the player's ids, a new one avoids clashes
 			m.RemoveItem(entry)
 			save.SaveAny("merchant", m)
 			save.SaveAny("player", player)
@@ -1,6 +1,7 @@
 package structures
 
 import (
+	"encoding/json"
 	"fmt"
 	"main/pkg/save"
 )
@@ -16,6 +17,20 @@ type Player struct {
 	XP             int
 	Spells         []Spell
 	IsFirstLogin   bool
+	KnownRecipes   []string
+	KnownBrews     []string
+	InventorySort  string // One of InventorySorts, kept between sessions
+	ItemCounter    int    // Last item instance id, see NewItemID
+
+	Dropped Inventory `json:"-"` // Loot that didn't fit, waiting to be dropped on the floor
+}
+
+type playerJSON Player // Same fields without the MarshalJSON method
+
+// Saves the item counter along with the player
+func (plr Player) MarshalJSON() ([]byte, error) {
+	plr.ItemCounter = ItemCounter()
+	return json.Marshal(playerJSON(plr))
 }
 
 func ApplySpellEffect(spell Spell, target *Entity) {
@@ -31,7 +46,7 @@ func ApplySpellEffect(spell Spell, target *Entity) {
 		target.Effects = append(target.Effects, Effect{
 			Name:     "Poisoned",
 			Duration: 2,
-			Modifier: 0.4, // Reduce defense by 40%
+			Modifier: 0.05, // 5% HP per turn
 		})
 
 	case "Lightning":
@@ -50,11 +65,31 @@ func ApplySpellEffect(spell Spell, target *Entity) {
 	}
 }
 
+func (plr *Player) MaxMana() int {
+	return 100 + plr.Race.BonusMana + plr.Weapon.AffixTotal("BonusMana") + plr.Entity.ArmorAffixTotal("BonusMana")
+}
+
+func (plr *Player) TotalInitiative() int {
+	return plr.Entity.Initiative + plr.Weapon.AffixTotal("Initiative") + plr.Entity.ArmorAffixTotal("Initiative") - plr.Encumbrance().InitiativePenalty
+}
+
 func (plr *Player) InflictDamage(action string, attackedEntity *Entity, spellUsed Spell, damageMultiplier float64) (int, int) {
+	if strength, ok := plr.Entity.EffectModifier("Strength"); ok {
+		damageMultiplier *= strength
+	}
 	switch action {
 	case "Melee":
-		rawDamage := int(float64(plr.Race.BonusDamage+plr.Weapon.Damage) * damageMultiplier)
-		actualDamage := attackedEntity.TakeDamage(rawDamage)
+		hits, hitMultiplier := plr.Weapon.MeleeHits()
+		rawDamage, actualDamage := 0, 0
+		for i := 0; i < hits && attackedEntity.Alive; i++ {
+			hitDamage := int(float64(plr.Race.BonusDamage+plr.Weapon.EffectiveDamage()) * damageMultiplier * hitMultiplier)
+			rawDamage += hitDamage
+			actualDamage += attackedEntity.TakeDamage(hitDamage)
+		}
+		plr.Entity.applyLifesteal(plr.Weapon.AffixTotal("Lifesteal")+plr.Entity.ArmorAffixTotal("Lifesteal"), actualDamage)
+		if attackedEntity.Alive && plr.Weapon.rollBleed(attackedEntity) {
+			fmt.Printf("%s is bleeding!\n", attackedEntity.Name)
+		}
 		return rawDamage, actualDamage
 	case "Spell":
 		if spellUsed.Cost <= plr.Mana {
@@ -89,7 +124,49 @@ func (plr *Player) AddXP(xp int) {
 	if plr.XP >= 100 {
 		fmt.Println("Leveled up!")
 		plr.LevelUp()
+		for _, r := range plr.UnlockMilestoneRecipes() {
+			fmt.Printf("New recipe unlocked: %s\n", r.Name)
+		}
+	}
+}
+
+func (plr *Player) KnowsRecipe(key string) bool {
+	for _, k := range plr.KnownRecipes {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (plr *Player) LearnRecipe(key string) bool {
+	if plr.KnowsRecipe(key) {
+		return false
+	}
+	if _, ok := GetRecipe(key); !ok {
+		return false
+	}
+	plr.KnownRecipes = append(plr.KnownRecipes, key)
+	return true
+}
+
+// Unlocks milestone recipes the player has reached, returns the newly unlocked ones
+func (plr *Player) UnlockMilestoneRecipes() []Recipe {
+	unlocked := []Recipe{}
+	for _, r := range AllRecipes {
+		if r.Unlock == "milestone" && plr.Entity.Level >= r.MinLevel && plr.LearnRecipe(r.Key) {
+			unlocked = append(unlocked, r)
+		}
 	}
+	return unlocked
+}
+
+func (plr *Player) UseRecipeScroll(s RecipeScroll) bool {
+	if !plr.LearnRecipe(s.RecipeKey) {
+		return false
+	}
+	plr.RemoveItem(s)
+	return true
 }
 
 func (plr *Player) CurrentCarryWeight() int {
@@ -100,8 +177,10 @@ func (plr *Player) CurrentCarryWeight() int {
 	return total
 }
 
+// Over MaxCarryWeight the player gets encumbered, only the hard maximum refuses items
 func (plr *Player) CanAddItem(entry InventoryEntry) bool {
-	return plr.CurrentCarryWeight()+entry.GetItem().Weight < plr.MaxCarryWeight // Not <= to allow to add materials (0weight) even if full
+	weight := entry.GetItem().Weight
+	return weight == 0 || plr.CurrentCarryWeight()+weight <= plr.HardCarryWeight() // Materials (0 weight) always fit
 }
 
 func (plr *Player) AddItem(entry InventoryEntry) bool {
@@ -112,6 +191,19 @@ func (plr *Player) AddItem(entry InventoryEntry) bool {
 	return false
 }
 
+// Adds as many entries as the carry weight allows, returns what was added and what was left
+func (plr *Player) AddItems(entries []InventoryEntry) ([]InventoryEntry, []InventoryEntry) {
+	added, left := []InventoryEntry{}, []InventoryEntry{}
+	for _, entry := range entries {
+		if plr.AddItem(entry) {
+			added = append(added, entry)
+		} else {
+			left = append(left, entry)
+		}
+	}
+	return added, left
+}
+
 func (plr *Player) RemoveItem(entry InventoryEntry) bool {
 	for i, item := range plr.Inventory {
 		if item.GetItem().Id == entry.GetItem().Id {
@@ -191,6 +283,17 @@ func (plr *Player) UsePotion(p Potion) bool {
 				}
 			case "Cure":
 				plr.HP = plr.MaxHP
+			case "Mana":
+				plr.Mana += 50 * p.Size
+				if plr.Mana > plr.MaxMana() {
+					plr.Mana = plr.MaxMana()
+				}
+			case "Strength":
+				plr.Entity.AddEffect(Effect{Name: "Strength", Duration: 6, Modifier: 1.3}) // +30% damage
+			case "Resistance":
+				plr.Entity.AddEffect(Effect{Name: "Resistance", Duration: 6, Modifier: 0.7}) // -30% damage taken
+			case "Haste":
+				plr.Entity.AddEffect(Effect{Name: "Haste", Duration: 6, Modifier: 8}) // +8 initiative
 			default:
 			}
 			plr.RemoveItem(entry)
@@ -212,6 +315,16 @@ func (plr *Player) UseBackpack(b BackpackItem) bool {
 	return false
 }
 
+// Slots added after the save was made are loaded without a name
+func (plr *Player) fillEmptySlots() {
+	slotTypes := []string{"Helmet", "Chestplate", "Boots", "Ring", "Ring", "Amulet", "Shield"}
+	for i, piece := range plr.Entity.EquippedArmor() {
+		if piece.Name == "" {
+			*piece = NoArmor(slotTypes[i])
+		}
+	}
+}
+
 func InitCharacter(username, race string) Player {
 	mainPlayer := Player{}
 	err := save.LoadAny("player", &mainPlayer)
@@ -238,6 +351,10 @@ func InitCharacter(username, race string) Player {
 					Type:    "Boots",
 					Defense: 0,
 				},
+				Ring1:      NoArmor("Ring"),
+				Ring2:      NoArmor("Ring"),
+				Amulet:     NoArmor("Amulet"),
+				Shield:     NoArmor("Shield"),
 				Initiative: 10,
 			},
 			Weapon:         AllWeapons["Sword"],
@@ -248,6 +365,7 @@ func InitCharacter(username, race string) Player {
 			MaxCarryWeight: 10,
 			Spells:         []Spell{AllSpells["HandPunch"]},
 			IsFirstLogin:   true,
+			KnownRecipes:   DefaultRecipeKeys(),
 		}
 		mainPlayer.Mana += mainPlayer.Race.BonusMana
 		mainPlayer.MaxHP += mainPlayer.Race.BonusHP
@@ -267,9 +385,20 @@ func InitCharacter(username, race string) Player {
 			mainPlayer.Race = AllRaces[race]
 		}
 		if mainPlayer.Weapon.Name == "" {
-			fmt.Printf("Fixing empty weapon, setting to Sword\n")
-			mainPlayer.Weapon = AllWeapons["Sword"]
+			if name, ok := GetWeaponNameById(mainPlayer.Weapon.Id); ok {
+				mainPlayer.Weapon.Name = name
+			} else {
+				fmt.Printf("Fixing empty weapon, setting to Sword\n")
+				mainPlayer.Weapon = AllWeapons["Sword"]
+			}
+		}
+		if mainPlayer.KnownRecipes == nil { // Saves from before the recipe book
+			mainPlayer.KnownRecipes = DefaultRecipeKeys()
 		}
+		mainPlayer.UnlockMilestoneRecipes()
+		mainPlayer.fillEmptySlots()
+		mainPlayer.migrateGear()
+		SyncItemCounter(mainPlayer.ItemCounter)
 	}
 
 	return mainPlayer
@@ -7,6 +7,7 @@ type Race struct {
 	Skill           Spell
 	BonusMana       int
 	BonusInitiative int
+	BonusPerception int // Chance to spot hidden traps
 }
 
 type EnemyRace struct {
@@ -34,6 +35,7 @@ var (
 		BonusMana:       70,
 		BonusDamage:     10,
 		BonusInitiative: 10,
+		BonusPerception: 25,
 		Skill:           AllSpells["HandPunch"],
 	}
 	Dwarf = Race{
@@ -42,6 +44,7 @@ var (
 		BonusMana:       30,
 		BonusDamage:     10,
 		BonusInitiative: 2,
+		BonusPerception: 15,
 		Skill:           AllSpells["HandPunch"],
 	}
 	Orc = EnemyRace{
@@ -65,6 +68,13 @@ var (
 		BonusInitiative: 7,
 		Drop:            "GoblinEar",
 	}
+	Mimic = EnemyRace{
+		Name:            "Mimic",
+		BonusHP:         30,
+		BonusDamage:     15,
+		BonusInitiative: 12,
+		Drop:            "ChestKey",
+	}
 )
 
 var AllRaces = map[string]Race{
@@ -77,4 +87,5 @@ var AllEnemyRaces = map[string]EnemyRace{
 	"Orc":      Orc,
 	"Skeleton": Skeleton,
 	"Goblin":   Goblin,
+	"Mimic":    Mimic,
 }
@@ -0,0 +1,115 @@
+package structures
+
+import "fmt"
+
+type Recipe struct {
+	Key        string
+	Name       string
+	OutputType string // "weapon" | "armor"
+	WeaponName string // if OutputType==weapon
+	ArmorType  string // Helmet/Chestplate/Boots if OutputType==armor
+	ArmorName  string
+	Materials  map[string]int
+	Minutes    int
+	Gold       int
+	Unlock     string // "default" | "milestone" | "scroll"
+	MinLevel   int    // Player level required for milestone recipes
+}
+
+var AllRecipes = []Recipe{
+	{Key: "WeaponSword", Name: "Weapon: Sword", OutputType: "weapon", WeaponName: "Sword",
+		Materials: map[string]int{"SkeletonBone": 2, "GoblinEar": 1}, Minutes: 2, Gold: 5, Unlock: "default"},
+	{Key: "WeaponAxe", Name: "Weapon: Axe", OutputType: "weapon", WeaponName: "Axe",
+		Materials: map[string]int{"OrcTusk": 3, "SkeletonBone": 2}, Minutes: 3, Gold: 5, Unlock: "default"},
+	{Key: "WeaponDoubleAxes", Name: "Weapon: DoubleAxes", OutputType: "weapon", WeaponName: "DoubleAxes",
+		Materials: map[string]int{"OrcTusk": 3, "SkeletonBone": 2}, Minutes: 3, Gold: 5, Unlock: "milestone", MinLevel: 2},
+	{Key: "WeaponSpear", Name: "Weapon: Spear", OutputType: "weapon", WeaponName: "Spear",
+		Materials: map[string]int{"OrcTusk": 3, "SkeletonBone": 2}, Minutes: 3, Gold: 5, Unlock: "scroll"},
+
+	{Key: "HelmetVoidWalker", Name: "Helmet: VoidWalker", OutputType: "armor", ArmorType: "Helmet", ArmorName: "VoidWalker",
+		Materials: map[string]int{"GoblinEar": 2}, Minutes: 3, Gold: 5, Unlock: "default"},
+	{Key: "HelmetSunBreaker", Name: "Helmet: SunBreaker", OutputType: "armor", ArmorType: "Helmet", ArmorName: "SunBreaker",
+		Materials: map[string]int{"OrcTusk": 2, "SkeletonBone": 2}, Minutes: 7, Gold: 5, Unlock: "milestone", MinLevel: 3},
+	{Key: "HelmetStormBringer", Name: "Helmet: StormBringer", OutputType: "armor", ArmorType: "Helmet", ArmorName: "StormBringer",
+		Materials: map[string]int{"OrcTusk": 4, "SkeletonBone": 2}, Minutes: 10, Gold: 5, Unlock: "scroll"},
+
+	{Key: "ChestplateVoidWalker", Name: "Chestplate: VoidWalker", OutputType: "armor", ArmorType: "Chestplate", ArmorName: "VoidWalker",
+		Materials: map[string]int{"GoblinEar": 4}, Minutes: 3, Gold: 8, Unlock: "default"},
+	{Key: "ChestplateSunBreaker", Name: "Chestplate: SunBreaker", OutputType: "armor", ArmorType: "Chestplate", ArmorName: "SunBreaker",
+		Materials: map[string]int{"OrcTusk": 4, "SkeletonBone": 4}, Minutes: 7, Gold: 8, Unlock: "milestone", MinLevel: 3},
+	{Key: "ChestplateStormBringer", Name: "Chestplate: StormBringer", OutputType: "armor", ArmorType: "Chestplate", ArmorName: "StormBringer",
+		Materials: map[string]int{"OrcTusk": 8, "SkeletonBone": 4}, Minutes: 10, Gold: 8, Unlock: "scroll"},
+
+	{Key: "BootsVoidWalker", Name: "Boots: VoidWalker", OutputType: "armor", ArmorType: "Boots", ArmorName: "VoidWalker",
+		Materials: map[string]int{"GoblinEar": 2}, Minutes: 3, Gold: 5, Unlock: "default"},
+	{Key: "BootsSunBreaker", Name: "Boots: SunBreaker", OutputType: "armor", ArmorType: "Boots", ArmorName: "SunBreaker",
+		Materials: map[string]int{"OrcTusk": 2, "SkeletonBone": 2}, Minutes: 7, Gold: 5, Unlock: "milestone", MinLevel: 3},
+	{Key: "BootsStormBringer", Name: "Boots: StormBringer", OutputType: "armor", ArmorType: "Boots", ArmorName: "StormBringer",
+		Materials: map[string]int{"OrcTusk": 4, "SkeletonBone": 2}, Minutes: 10, Gold: 5, Unlock: "scroll"},
+}
+
+func GetRecipe(key string) (Recipe, bool) {
+	for _, r := range AllRecipes {
+		if r.Key == key {
+			return r, true
+		}
+	}
+	return Recipe{}, false
+}
+
+func DefaultRecipeKeys() []string {
+	keys := []string{}
+	for _, r := range AllRecipes {
+		if r.Unlock == "default" {
+			keys = append(keys, r.Key)
+		}
+	}
+	return keys
+}
+
+// Describes how a locked recipe can be unlocked, for the recipe book
+func (r Recipe) UnlockHint() string {
+	switch r.Unlock {
+	case "milestone":
+		return fmt.Sprintf("Reach level %d", r.MinLevel)
+	case "scroll":
+		return "Find its recipe scroll"
+	default:
+		return "Known from the start"
+	}
+}
+
+type RecipeScroll struct {
+	Item
+	RecipeKey string
+}
+
+func (s RecipeScroll) GetItem() Item { return s.Item }
+
+func NewRecipeScroll(recipe Recipe) RecipeScroll {
+	item := NewItem("Recipe Scroll: "+recipe.Name, 0, 150, 4)
+	item.Id = NewItemID()
+	item.TemplateKey = "recipe:" + recipe.Key
+	return RecipeScroll{
+		Item:      item,
+		RecipeKey: recipe.Key,
+	}
+}
+
+// Rolls a recipe scroll drop for a recipe the player doesn't know yet, bosses always drop one
+func RollRecipeScroll(player *Player, isBoss bool) (RecipeScroll, bool) {
+	pool := []Recipe{}
+	for _, r := range AllRecipes {
+		if r.Unlock == "scroll" && !player.KnowsRecipe(r.Key) {
+			pool = append(pool, r)
+		}
+	}
+	if len(pool) == 0 {
+		return RecipeScroll{}, false
+	}
+	rng := GetRNG()
+	if !isBoss && rng.Intn(100) >= 10 { // 10% chance on regular mobs
+		return RecipeScroll{}, false
+	}
+	return NewRecipeScroll(pool[rng.Intn(len(pool))]), true
+}
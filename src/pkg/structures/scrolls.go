@@ -0,0 +1,35 @@
+package structures
+
+// Read from the inventory, the effect is handled by the game since it acts on the dungeon
+type Scroll struct {
+	Item
+	Type string // TownPortal | MagicMapping | Teleport | Identify
+}
+
+func (s Scroll) GetItem() Item { return s.Item }
+
+var (
+	TownPortalScroll = Scroll{
+		Item: NewItem("Town Portal Scroll", 1, 120, 3),
+		Type: "TownPortal",
+	}
+	MagicMappingScroll = Scroll{
+		Item: NewItem("Magic Mapping Scroll", 1, 90, 3),
+		Type: "MagicMapping",
+	}
+	TeleportScroll = Scroll{
+		Item: NewItem("Teleport Scroll", 1, 60, 2),
+		Type: "Teleport",
+	}
+	IdentifyScroll = Scroll{
+		Item: NewItem("Identify Scroll", 1, 40, 2),
+		Type: "Identify",
+	}
+)
+
+var AllScrolls = map[string]Scroll{
+	"TownPortal":   TownPortalScroll,
+	"MagicMapping": MagicMappingScroll,
+	"Teleport":     TeleportScroll,
+	"Identify":     IdentifyScroll,
+}
@@ -0,0 +1,94 @@
+package structures
+
+import "fmt"
+
+type SetBonus struct {
+	Pieces      int // Helmet/Chestplate/Boots pieces needed
+	Defense     int
+	Effect      string // "" | "LightningProc" | "Dodge" | "Regen"
+	Value       int    // Effect strength: damage, % chance or HP
+	Description string
+}
+
+type ArmorSet struct {
+	Name    string
+	Bonuses []SetBonus
+}
+
+// Bonuses stack, wearing 3 pieces also gives the 2 pieces bonus. Descriptions show the total
+var AllSets = []ArmorSet{
+	{Name: "StormBringer", Bonuses: []SetBonus{
+		{Pieces: 2, Defense: 3, Description: "+3 defense"},
+		{Pieces: 3, Defense: 3, Effect: "LightningProc", Value: 25, Description: "+6 defense, perfect blocks strike back with lightning"},
+	}},
+	{Name: "SunBreaker", Bonuses: []SetBonus{
+		{Pieces: 2, Defense: 2, Description: "+2 defense"},
+		{Pieces: 3, Defense: 2, Effect: "Regen", Value: 5, Description: "+4 defense, regenerate 5 HP each turn"},
+	}},
+	{Name: "VoidWalker", Bonuses: []SetBonus{
+		{Pieces: 2, Defense: 1, Description: "+1 defense"},
+		{Pieces: 3, Defense: 1, Effect: "Dodge", Value: 15, Description: "+2 defense, 15% chance to dodge attacks"},
+	}},
+}
+
+func (ent *Entity) SetPieceCount(setName string) int {
+	count := 0
+	for _, piece := range []Armors{ent.Helmet, ent.Chestplate, ent.Boots} {
+		if piece.Name == setName && !piece.IsBroken() {
+			count++
+		}
+	}
+	return count
+}
+
+func (ent *Entity) ActiveSetBonuses() []SetBonus {
+	active := []SetBonus{}
+	for _, set := range AllSets {
+		count := ent.SetPieceCount(set.Name)
+		for _, bonus := range set.Bonuses {
+			if count >= bonus.Pieces {
+				active = append(active, bonus)
+			}
+		}
+	}
+	return active
+}
+
+func GetSetBonusDefense(ent Entity) int {
+	total := 0
+	for _, bonus := range ent.ActiveSetBonuses() {
+		total += bonus.Defense
+	}
+	return total
+}
+
+func (ent *Entity) SetEffectValue(effect string) (int, bool) {
+	for _, bonus := range ent.ActiveSetBonuses() {
+		if bonus.Effect == effect {
+			return bonus.Value, true
+		}
+	}
+	return 0, false
+}
+
+// One line per set being worn, eg "StormBringer 2/3: +3 defense (next bonus at 3)"
+func (ent *Entity) SetProgress() []string {
+	lines := []string{}
+	for _, set := range AllSets {
+		count := ent.SetPieceCount(set.Name)
+		if count == 0 {
+			continue
+		}
+		maxPieces := set.Bonuses[len(set.Bonuses)-1].Pieces
+		line := fmt.Sprintf("%s %d/%d", set.Name, count, maxPieces)
+		for _, bonus := range set.Bonuses {
+			if count < bonus.Pieces {
+				line += fmt.Sprintf(" (next bonus at %d)", bonus.Pieces)
+				break
+			}
+			line = fmt.Sprintf("%s %d/%d: %s", set.Name, count, maxPieces, bonus.Description)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,32 @@
+package structures
+
+// Stashes ignore the carry weight but hold a limited number of items
+const MaxStashItems = 60
+
+type Stash struct {
+	Items Inventory
+}
+
+func (s *Stash) IsFull() bool {
+	return len(s.Items) >= MaxStashItems
+}
+
+// Moves the inventory entry at index into the stash
+func (s *Stash) Deposit(plr *Player, index int) bool {
+	if index < 0 || index >= len(plr.Inventory) || s.IsFull() {
+		return false
+	}
+	s.Items = append(s.Items, plr.Inventory[index])
+	plr.Inventory = append(plr.Inventory[:index], plr.Inventory[index+1:]...)
+	return true
+}
+
+// Moves the stash entry at index back into the inventory, if the player can carry it
+// It gets a new id, items from the shared stash come from another character's counter
+func (s *Stash) Withdraw(plr *Player, index int) bool {
+	if index < 0 || index >= len(s.Items) || !plr.AddItem(NewInstance(s.Items[index])) {
+		return false
+	}
+	s.Items = append(s.Items[:index], s.Items[index+1:]...)
+	return true
+}
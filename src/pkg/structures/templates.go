@@ -0,0 +1,310 @@
+package structures
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Every item that can exist, keyed by a stable template key ("potion:Heal", "weapon:Sword"...)
+// Saves only reference the key, so balance changes to a template reach existing saves
+var ItemTemplates = map[string]InventoryEntry{}
+
+// Last instance id handed out, saved with the player
+var itemCounter int
+
+func NewItemID() int {
+	itemCounter++
+	return itemCounter
+}
+
+func ItemCounter() int {
+	return itemCounter
+}
+
+// Never goes back, ids already handed out stay unique
+func SyncItemCounter(n int) {
+	if n > itemCounter {
+		itemCounter = n
+	}
+}
+
+func init() {
+	for key, m := range AllMaterials {
+		AllMaterials[key] = registerTemplate("material:"+key, m).(Material)
+	}
+	for key, p := range AllPotions {
+		AllPotions[key] = registerTemplate("potion:"+p.Type, p).(Potion)
+	}
+	for key, sb := range AllSpellbooks {
+		AllSpellbooks[key] = registerTemplate("spellbook:"+key, sb).(Spellbooks)
+	}
+	for key, b := range AllBackpacks {
+		AllBackpacks[key] = registerTemplate("backpack:"+key, b).(BackpackItem)
+	}
+	for key, sc := range AllScrolls {
+		AllScrolls[key] = registerTemplate("scroll:"+key, sc).(Scroll)
+	}
+	for key, w := range AllWeapons {
+		registerTemplate("weapon:"+key, NewWeaponItem(w))
+	}
+	for _, slot := range []map[string]Armors{AllHelmets, AllChestplates, AllBoots, AllRings, AllAmulets, AllShields} {
+		for _, a := range slot {
+			registerTemplate(armorTemplateKey(a), NewArmorItem(a))
+		}
+	}
+	for _, r := range AllRecipes {
+		registerTemplate("recipe:"+r.Key, NewRecipeScroll(r))
+	}
+	itemCounter = 0 // Templates don't use up instance ids
+}
+
+func armorTemplateKey(a Armors) string {
+	return "armor:" + a.Type + ":" + a.Name
+}
+
+func registerTemplate(key string, entry InventoryEntry) InventoryEntry {
+	item := entry.GetItem()
+	item.Id = 0 // Templates are not instances
+	item.TemplateKey = key
+	entry = withItem(entry, item)
+	ItemTemplates[key] = entry
+	return entry
+}
+
+// Sorted template keys, map order is random and drops must follow the seed
+func TemplateKeys() []string {
+	keys := make([]string, 0, len(ItemTemplates))
+	for key := range ItemTemplates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func withItem(entry InventoryEntry, item Item) InventoryEntry {
+	switch e := entry.(type) {
+	case Material:
+		e.Item = item
+		return e
+	case Potion:
+		e.Item = item
+		return e
+	case Spellbooks:
+		e.Item = item
+		return e
+	case BackpackItem:
+		e.Item = item
+		return e
+	case WeaponItem:
+		e.Item = item
+		return e
+	case ArmorItem:
+		e.Item = item
+		return e
+	case RecipeScroll:
+		e.Item = item
+		return e
+	case Scroll:
+		e.Item = item
+		return e
+	case Item:
+		return item
+	}
+	return entry
+}
+
+// Copy of the entry with its own instance id
+func NewInstance(entry InventoryEntry) InventoryEntry {
+	item := entry.GetItem()
+	item.Id = NewItemID()
+	return withItem(entry, item)
+}
+
+// Creates a new instance of a template, false if the key is unknown
+func NewFromTemplate(key string) (InventoryEntry, bool) {
+	entry, ok := ItemTemplates[key]
+	if !ok {
+		return nil, false
+	}
+	return NewInstance(entry), true
+}
+
+// Template stats with the instance tier, affixes and wear
+func weaponFromTemplate(w Weapon) Weapon {
+	tpl, ok := AllWeapons[w.Name]
+	if !ok {
+		return w
+	}
+	tpl.Tier = w.Tier
+	tpl.Affixes = w.Affixes
+	tpl.Unidentified = w.Unidentified
+	tpl.Cursed = w.Cursed
+	tpl.Durability = w.Durability
+	if tpl.Durability > tpl.MaxDurability {
+		tpl.Durability = tpl.MaxDurability
+	}
+	return tpl
+}
+
+func armorFromTemplate(a Armors) Armors {
+	tpl := GetArmorByType(a.Type, a.Name)
+	if tpl.Name != a.Name {
+		return a
+	}
+	tpl.Tier = a.Tier
+	tpl.Affixes = a.Affixes
+	tpl.Unidentified = a.Unidentified
+	tpl.Cursed = a.Cursed
+	tpl.Durability = a.Durability
+	if tpl.Durability > tpl.MaxDurability {
+		tpl.Durability = tpl.MaxDurability
+	}
+	return tpl
+}
+
+// What a save keeps of an item, the rest comes from its template
+type savedEntry struct {
+	Template     string  `json:"template"`
+	Id           int     `json:"id"`
+	Tier         string  `json:"tier,omitempty"`
+	Affixes      []Affix `json:"affixes,omitempty"`
+	Durability   *int    `json:"durability,omitempty"`
+	Unidentified bool    `json:"unidentified,omitempty"`
+	Cursed       bool    `json:"cursed,omitempty"`
+	Size         int     `json:"size,omitempty"`  // Potions
+	Price        *int    `json:"price,omitempty"` // Only when it differs from the template (eg. the free potion)
+}
+
+func toSavedEntry(entry InventoryEntry) savedEntry {
+	item := entry.GetItem()
+	s := savedEntry{Template: item.TemplateKey, Id: item.Id}
+	if tpl, ok := ItemTemplates[item.TemplateKey]; ok && tpl.GetItem().Price != item.Price {
+		price := item.Price
+		s.Price = &price
+	}
+	switch e := entry.(type) {
+	case WeaponItem:
+		s.Tier = e.Weapon.Tier
+		s.Affixes = e.Weapon.Affixes
+		s.Durability = &e.Weapon.Durability
+		s.Unidentified, s.Cursed = e.Weapon.Unidentified, e.Weapon.Cursed
+		s.Price = nil // Gear prices come from the tier
+	case ArmorItem:
+		s.Tier = e.Armor.Tier
+		s.Affixes = e.Armor.Affixes
+		s.Durability = &e.Armor.Durability
+		s.Unidentified, s.Cursed = e.Armor.Unidentified, e.Armor.Cursed
+		s.Price = nil
+	case Potion:
+		s.Size = e.Size
+	}
+	return s
+}
+
+func fromSavedEntry(s savedEntry) (InventoryEntry, bool) {
+	tpl, ok := ItemTemplates[s.Template]
+	if !ok {
+		return nil, false
+	}
+	var entry InventoryEntry
+	switch e := tpl.(type) {
+	case WeaponItem:
+		w := e.Weapon
+		w.Tier, w.Affixes = s.Tier, s.Affixes
+		w.Unidentified, w.Cursed = s.Unidentified, s.Cursed
+		if s.Durability != nil {
+			w.Durability = *s.Durability
+		}
+		entry = NewWeaponItem(weaponFromTemplate(w))
+	case ArmorItem:
+		a := e.Armor
+		a.Tier, a.Affixes = s.Tier, s.Affixes
+		a.Unidentified, a.Cursed = s.Unidentified, s.Cursed
+		if s.Durability != nil {
+			a.Durability = *s.Durability
+		}
+		entry = NewArmorItem(armorFromTemplate(a))
+	case Potion:
+		if s.Size > 0 {
+			e.Size = s.Size
+		}
+		entry = e
+	default:
+		entry = tpl
+	}
+	item := entry.GetItem()
+	item.Id = s.Id
+	item.TemplateKey = s.Template
+	if s.Price != nil {
+		item.Price = *s.Price
+	}
+	return withItem(entry, item), true
+}
+
+// Entries without a known template are kept in full, the old way
+func (inv Inventory) MarshalJSON() ([]byte, error) {
+	saved := make([]any, 0, len(inv))
+	for _, entry := range inv {
+		if _, ok := ItemTemplates[entry.GetItem().TemplateKey]; ok {
+			saved = append(saved, toSavedEntry(entry))
+		} else {
+			saved = append(saved, entry)
+		}
+	}
+	return json.Marshal(saved)
+}
+
+// Template key of entries from saves made before the registry
+func legacyTemplateKey(entry InventoryEntry) string {
+	switch e := entry.(type) {
+	case Material:
+		return "material:" + e.Key
+	case Potion:
+		return "potion:" + e.Type
+	case Spellbooks:
+		for key, sb := range AllSpellbooks {
+			if sb.Spell.Name == e.Spell.Name {
+				return "spellbook:" + key
+			}
+		}
+	case BackpackItem:
+		for key, b := range AllBackpacks {
+			if b.Name == e.Name {
+				return "backpack:" + key
+			}
+		}
+	case WeaponItem:
+		return "weapon:" + e.Weapon.Name
+	case ArmorItem:
+		return armorTemplateKey(e.Armor)
+	case RecipeScroll:
+		return "recipe:" + e.RecipeKey
+	case Scroll:
+		return "scroll:" + e.Type
+	}
+	return ""
+}
+
+// Entries from saves made before the registry get a template and a fresh id
+func fromLegacyEntry(entry InventoryEntry) InventoryEntry {
+	switch e := entry.(type) {
+	case WeaponItem:
+		fillWeaponCategory(&e.Weapon)
+		fillWeaponDurability(&e.Weapon)
+		entry = e
+	case ArmorItem:
+		fillArmorDurability(&e.Armor)
+		entry = e
+	}
+	item := entry.GetItem()
+	item.TemplateKey = legacyTemplateKey(entry)
+	item.Id = NewItemID()
+	entry = withItem(entry, item)
+	if _, ok := ItemTemplates[item.TemplateKey]; !ok {
+		return entry
+	}
+	if converted, ok := fromSavedEntry(toSavedEntry(entry)); ok {
+		return converted
+	}
+	return entry
+}
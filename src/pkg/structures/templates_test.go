@@ -0,0 +1,55 @@
+package structures
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInventoryRoundTripKeepsInstances(t *testing.T) {
+	sword := NewInstance(ItemTemplates["weapon:Sword"]).(WeaponItem)
+	sword.Weapon.Durability = 7
+	potion := NewInstance(AllPotions["Heal"]).(Potion)
+	inv := Inventory{sword, potion}
+
+	data, err := json.Marshal(inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	itemCounter = 0
+	var loaded Inventory
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != 2 {
+		t.Fatalf("loaded %d entries, want 2", len(loaded))
+	}
+	gotSword, ok := loaded[0].(WeaponItem)
+	if !ok || gotSword.Id != sword.Id || gotSword.Weapon.Durability != 7 || gotSword.TemplateKey != "weapon:Sword" {
+		t.Fatalf("sword loaded as %+v", loaded[0])
+	}
+	gotPotion, ok := loaded[1].(Potion)
+	if !ok || gotPotion.Id != potion.Id || gotPotion.Name != AllPotions["Heal"].Name {
+		t.Fatalf("potion loaded as %+v", loaded[1])
+	}
+	if ItemCounter() < potion.Id {
+		t.Fatalf("item counter = %d after loading id %d, new ids would clash", ItemCounter(), potion.Id)
+	}
+}
+
+func TestLegacyEntryGetsTemplateAndNewID(t *testing.T) {
+	itemCounter = 10
+	legacy := `[{"Name":"Heal Potion","Id":3,"Weight":1,"Price":50,"Rarity":1,"Size":1,"Type":"Heal"}]`
+	var loaded Inventory
+	if err := json.Unmarshal([]byte(legacy), &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	potion, ok := loaded[0].(Potion)
+	if !ok || potion.TemplateKey != "potion:Heal" {
+		t.Fatalf("legacy potion loaded as %+v", loaded[0])
+	}
+	if potion.Id != 11 {
+		t.Fatalf("legacy potion id = %d, want a fresh id 11", potion.Id)
+	}
+}
@@ -0,0 +1,66 @@
+package structures
+
+const (
+	baseSearchChance = 40
+	maxSearchChance  = 95
+	baseDisarmChance = 30
+	maxDisarmChance  = 90
+)
+
+var (
+	ScrapMetal = NewMaterial("ScrapMetal", "Scrap Metal")
+	ArcaneDust = NewMaterial("ArcaneDust", "Arcane Dust")
+)
+
+// What a disarmed trap leaves, by trap name
+var TrapMaterials = map[string][]string{
+	"Spike trap":    {"ScrapMetal", "ScrapMetal"},
+	"Gas trap":      {"ScrapMetal", "Ashcap"},
+	"Alarm trap":    {"ScrapMetal"},
+	"Teleport trap": {"ArcaneDust"},
+}
+
+// Chance to notice a hidden trap close by without looking for it, elves and dwarves have a knack for it
+func (plr *Player) Perception() int {
+	return plr.Race.BonusPerception
+}
+
+func (plr *Player) SearchChance() int {
+	chance := baseSearchChance + plr.Perception() + plr.Entity.Level*2
+	if chance > maxSearchChance {
+		chance = maxSearchChance
+	}
+	return chance
+}
+
+// Steady and nimble hands disarm traps
+func (plr *Player) DisarmChance() int {
+	chance := baseDisarmChance + plr.Perception()/2 + plr.TotalInitiative()
+	if chance > maxDisarmChance {
+		chance = maxDisarmChance
+	}
+	return chance
+}
+
+// Returns the parts of the trap if it was disarmed, a failed attempt sets it off
+func (plr *Player) DisarmTrap(name string) ([]InventoryEntry, bool) {
+	if GetRNG().Intn(100) >= plr.DisarmChance() {
+		RefreshSeedState()
+		return nil, false
+	}
+	parts := []InventoryEntry{}
+	for _, key := range TrapMaterials[name] {
+		parts = append(parts, NewInstance(AllMaterials[key]))
+	}
+	RefreshSeedState()
+	return parts, true
+}
+
+// Spikes hit harder deeper in the dungeon
+func TrapDamage(depth int) int {
+	return 8 + depth*2 + GetRNG().Intn(6)
+}
+
+func TrapPoison() Effect {
+	return Effect{Name: "Poisoned", Duration: 3, Modifier: 0.05} // 5% HP per turn like the poison spells
+}
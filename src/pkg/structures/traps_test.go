@@ -0,0 +1,33 @@
+package structures
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPoisonDealsDamageEachTurn(t *testing.T) {
+	entity := Entity{HP: 100, MaxHP: 100, Name: "Hero", Alive: true}
+	entity.AddEffect(TrapPoison())
+
+	TickEffects(&entity)
+	if entity.HP != 95 {
+		t.Fatalf("HP after one turn of poison = %d, want 95", entity.HP)
+	}
+	for len(entity.Effects) > 0 {
+		TickEffects(&entity)
+	}
+	if entity.HP != 85 {
+		t.Fatalf("HP once the poison wore off = %d, want 85", entity.HP)
+	}
+}
+
+func TestPoisonMessageShowsDamageAfterArmor(t *testing.T) {
+	entity := Entity{HP: 100, MaxHP: 100, Name: "Hero", Alive: true, Chestplate: Armors{Defense: 20}}
+	entity.AddEffect(TrapPoison())
+
+	messages := TickEffects(&entity)
+	want := fmt.Sprintf("Hero takes %d poison damage!", 100-entity.HP)
+	if len(messages) != 1 || messages[0] != want {
+		t.Fatalf("messages = %q, want %q", messages, want)
+	}
+}
@@ -1,31 +1,52 @@
 package structures
 
 type Weapon struct {
-	Damage int
-	Name   string
-	Id     int
+	Damage   int
+	Name     string
+	Id       int
+	Category string // Sword | Axe | DualAxes | Spear, gives the weapon its mechanics
+	Tier     string
+	Affixes  []Affix
+
+	Unidentified bool // Name and stats hidden until identified
+	Cursed       bool // Can't be unequipped until the curse is lifted
+
+	Durability    int
+	MaxDurability int
 }
 
 var (
 	Sword = Weapon{
-		Name:   "Sword",
-		Damage: 20,
-		Id:     1,
+		Name:          "Sword",
+		Category:      "Sword",
+		Damage:        20,
+		Id:            1,
+		Durability:    80,
+		MaxDurability: 80,
 	}
 	Axe = Weapon{
-		Name:   "Axe",
-		Damage: 25,
-		Id:     2,
+		Name:          "Axe",
+		Category:      "Axe",
+		Damage:        25,
+		Id:            2,
+		Durability:    100,
+		MaxDurability: 100,
 	}
 	DoubleAxes = Weapon{
-		Name:   "DoubleAxes",
-		Damage: 33,
-		Id:     3,
+		Name:          "DoubleAxes",
+		Category:      "DualAxes",
+		Damage:        33,
+		Id:            3,
+		Durability:    90,
+		MaxDurability: 90,
 	}
 	Spear = Weapon{
-		Name:   "Spear",
-		Damage: 50,
-		Id:     4,
+		Name:          "Spear",
+		Category:      "Spear",
+		Damage:        50,
+		Id:            4,
+		Durability:    70,
+		MaxDurability: 70,
 	}
 )
 
@@ -35,3 +56,64 @@ var AllWeapons = map[string]Weapon{
 	"DoubleAxes": DoubleAxes,
 	"Spear":      Spear,
 }
+
+// Embedded weapons lose their Name in saves (it clashes with the entity name), the Id is kept
+func GetWeaponNameById(id int) (string, bool) {
+	for name, w := range AllWeapons {
+		if w.Id == id {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Number of hits of a melee attack and the damage multiplier of each hit
+func (w Weapon) MeleeHits() (int, float64) {
+	if w.Category == "DualAxes" {
+		return 2, 0.6
+	}
+	return 1, 1.0
+}
+
+// Axes have a 25% chance to make the target bleed, returns true if it did
+func (w Weapon) rollBleed(target *Entity) bool {
+	if w.Category != "Axe" || GetRNG().Intn(100) >= 25 {
+		return false
+	}
+	target.AddEffect(Effect{Name: "Bleed", Duration: 3, Modifier: 0.03}) // 3% HP per turn
+	return true
+}
+
+// Spears always strike first on the opening turn
+func (w Weapon) StrikesFirst() bool {
+	return w.Category == "Spear"
+}
+
+// Swords parry, a partial QTE block lets half as much damage through
+func (w Weapon) ParryMultiplier(damageMultiplier float64) float64 {
+	if w.Category == "Sword" && damageMultiplier > 0 && damageMultiplier < 1 {
+		return damageMultiplier * 0.5
+	}
+	return damageMultiplier
+}
+
+func (w Weapon) MechanicDescription() string {
+	switch w.Category {
+	case "Sword":
+		return "Parries: partial blocks let half as much damage through"
+	case "Axe":
+		return "25% chance to make the target bleed"
+	case "DualAxes":
+		return "Hits twice at 60% damage"
+	case "Spear":
+		return "Always strikes first"
+	}
+	return ""
+}
+
+// Saves from before categories get the category of their template
+func fillWeaponCategory(w *Weapon) {
+	if w.Category == "" {
+		w.Category = AllWeapons[w.Name].Category
+	}
+}
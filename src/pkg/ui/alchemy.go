@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"main/pkg/structures"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+var (
+	alchemySelected int
+	cauldron        map[string]int
+)
+
+// Material keys the player owns, sorted so the list doesn't move around
+func alchemyMaterialKeys(player *structures.Player) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, entry := range player.Inventory {
+		if m, ok := entry.(structures.Material); ok && !seen[m.Key] && m.Key != structures.ChestKey.Key {
+			seen[m.Key] = true
+			keys = append(keys, m.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func addToCauldron(g *gocui.Gui, player *structures.Player) error {
+	keys := alchemyMaterialKeys(player)
+	if !IsValidIndex(alchemySelected, len(keys)) {
+		return nil
+	}
+	key := keys[alchemySelected]
+	if cauldron[key] >= player.CountMaterial(key) {
+		return ShowMessageWithOk(g, "alchemy", "Alchemist", "You don't have any more of this", 50, 7)
+	}
+	cauldron[key]++
+	return nil
+}
+
+func attemptBrew(g *gocui.Gui, player *structures.Player) error {
+	if len(cauldron) == 0 {
+		return ShowMessageWithOk(g, "alchemy", "Alchemist", "The cauldron is empty", 50, 7)
+	}
+	result, ok := player.Brew(cauldron)
+	if !ok {
+		return ShowMessageWithOk(g, "alchemy", "Alchemist", "Brew failed: missing ingredients or inventory too heavy", 60, 7)
+	}
+	cauldron = map[string]int{}
+	keys := alchemyMaterialKeys(player)
+	if alchemySelected >= len(keys) && alchemySelected > 0 {
+		alchemySelected = len(keys) - 1
+	}
+	if result.Success {
+		if result.Discovered {
+			return ShowMessageWithOk(g, "alchemy", "Alchemist", fmt.Sprintf("New recipe discovered: %s!", result.Potion.Name), 50, 7)
+		}
+		return ShowMessageWithOk(g, "alchemy", "Alchemist", fmt.Sprintf("Brewed %s", result.Potion.Name), 50, 7)
+	}
+	if result.Close {
+		return ShowMessageWithOk(g, "alchemy", "Alchemist", "The mixture bubbles... you're on the right track", 55, 7)
+	}
+	return ShowMessageWithOk(g, "alchemy", "Alchemist", "The mixture turns to sludge", 50, 7)
+}
+
+func ShowAlchemyMenu(player *structures.Player) {
+	alchemySelected = 0
+	cauldron = map[string]int{}
+	g, _ := gocui.NewGui(gocui.OutputNormal, false)
+	defer g.Close()
+
+	g.SetManagerFunc(func(g *gocui.Gui) error { return alchemyLayout(g, player) })
+	alchemyKeybindings(g, player)
+	g.MainLoop()
+}
+
+func alchemyLayout(g *gocui.Gui, player *structures.Player) error {
+	maxX, maxY := g.Size()
+
+	if err := SetOrUpdateView(g, "alchemy_title", 0, 0, maxX-1, 2, func(v *gocui.View) {
+		v.Frame = false
+	}, func(v *gocui.View) {
+		fmt.Fprintln(v, "  Alchemist • Enter=Add ingredient  B=Brew  C=Empty cauldron  ESC=Leave")
+	}); err != nil {
+		return err
+	}
+
+	listWidth := maxX - 2
+	listHeight := maxY - 6
+	if listHeight < 5 {
+		listHeight = 5
+	}
+	leftWidth := listWidth / 2
+	rightStartX := 1 + leftWidth + 1
+	if v, err := g.SetView("alchemy_list", 1, 3, 1+leftWidth, 3+listHeight, 0); err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.Title = " Ingredients "
+		v.Highlight = false
+	}
+
+	keys := alchemyMaterialKeys(player)
+	if v, err := g.View("alchemy_list"); err == nil {
+		v.Clear()
+		if len(keys) == 0 {
+			fmt.Fprintln(v, "(No ingredients, gather herbs in the dungeon)")
+		} else {
+			lines := make([]string, 0, len(keys))
+			for _, key := range keys {
+				lines = append(lines, fmt.Sprintf("%s x%d", structures.AllMaterials[key].Name, player.CountMaterial(key)-cauldron[key]))
+			}
+			RenderListWithHighlight(v, lines, alchemySelected)
+		}
+	}
+
+	if v, err := g.SetView("alchemy_cauldron", rightStartX, 3, 1+listWidth, 3+listHeight, 0); err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.Title = " Cauldron "
+		v.Highlight = false
+	}
+	if v, err := g.View("alchemy_cauldron"); err == nil {
+		v.Clear()
+		if len(cauldron) == 0 {
+			fmt.Fprintln(v, "(Empty)")
+		}
+		for _, key := range alchemyCauldronKeys() {
+			fmt.Fprintf(v, "%s x%d\n", structures.AllMaterials[key].Name, cauldron[key])
+		}
+		fmt.Fprintln(v, "\nKnown recipes:")
+		if len(player.KnownBrews) == 0 {
+			fmt.Fprintln(v, "  (None yet, experiment!)")
+		}
+		for _, brewKey := range player.KnownBrews {
+			recipe, ok := structures.GetBrewRecipe(brewKey)
+			if !ok {
+				continue
+			}
+			ingredients := ""
+			for key, amt := range recipe.Ingredients {
+				if ingredients != "" {
+					ingredients += ", "
+				}
+				ingredients += fmt.Sprintf("%dx %s", amt, structures.AllMaterials[key].Name)
+			}
+			fmt.Fprintf(v, "  %s: %s\n", structures.AllPotions[recipe.Potion].Name, ingredients)
+		}
+	}
+
+	btnY := maxY - 3
+	if btnY < 3 {
+		btnY = 3
+	}
+	closeBtnX := maxX - 12
+	if closeBtnX < 2 {
+		closeBtnX = 2
+	}
+	createButton(g, "alchemy_brew", " Brew ", closeBtnX-24, btnY, 10, 2, "alchemy_brew")
+	createButton(g, "alchemy_clear", " Empty ", closeBtnX-12, btnY, 10, 2, "alchemy_clear")
+	createButton(g, "alchemy_close", " Close ", closeBtnX, btnY, 10, 2, "alchemy_close")
+
+	if _, err := g.View("alchemy_msg"); err != nil {
+		g.SetCurrentView("alchemy_list")
+	}
+	return nil
+}
+
+func alchemyCauldronKeys() []string {
+	keys := make([]string, 0, len(cauldron))
+	for key := range cauldron {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func alchemyKeybindings(g *gocui.Gui, player *structures.Player) error {
+	BindQuitOnEsc(g)
+
+	BindListNavigation(g, "alchemy_list", &alchemySelected, func() int { return len(alchemyMaterialKeys(player)) })
+
+	g.SetKeybinding("alchemy_list", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return addToCauldron(g, player)
+	})
+	g.SetKeybinding("alchemy_list", 'b', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return attemptBrew(g, player)
+	})
+	g.SetKeybinding("alchemy_list", 'c', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		cauldron = map[string]int{}
+		return nil
+	})
+
+	EnableMouseAndSetHandler(g, func(g *gocui.Gui, v *gocui.View) error {
+		mx, my := g.MousePosition()
+
+		if listView, _ := g.View("alchemy_list"); listView != nil {
+			x0, y0, x1, y1 := listView.Dimensions()
+			if mx >= x0 && mx <= x1 && my >= y0 && my <= y1 {
+				idx := my - y0 - 1
+				if IsValidIndex(idx, len(alchemyMaterialKeys(player))) {
+					alchemySelected = idx
+					return addToCauldron(g, player)
+				}
+			}
+		}
+
+		buttons := []ButtonHandler{
+			{"alchemy_brew", func(g *gocui.Gui, v *gocui.View) error { return attemptBrew(g, player) }},
+			{"alchemy_clear", func(g *gocui.Gui, v *gocui.View) error {
+				cauldron = map[string]int{}
+				return nil
+			}},
+			{"alchemy_close", func(g *gocui.Gui, v *gocui.View) error { return gocui.ErrQuit }},
+			{"alchemy_ok", func(g *gocui.Gui, v *gocui.View) error {
+				DeleteViews(g, "alchemy_msg", "alchemy_ok")
+				return nil
+			}},
+		}
+		return HandleMouseClickButtons(g, mx, my, buttons)
+	})
+	return nil
+}
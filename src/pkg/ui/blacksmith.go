@@ -14,167 +14,21 @@ import (
 
 var (
 	blacksmithSelected int
-	cachedCraftEntries []craftEntry
 )
 
-type craftEntry struct {
-	label      string
-	kind       string // weapon | armor
-	weaponName string
-	armorType  string // Helmet | Chestplate | Boots
-	armorName  string
+func buildCraftEntries() []structures.Recipe {
+	return structures.AllRecipes
 }
 
-func getSortedKeys[T any](m map[string]T) []string { // Need to sort to avoid random order
-	keys := make([]string, 0, len(m))
-	for key := range m {
-		keys = append(keys, key)
+func recipeLabel(r structures.Recipe, player *structures.Player) string {
+	if player.KnowsRecipe(r.Key) {
+		return r.Name
 	}
-	sort.Strings(keys)
-	return keys
-}
-
-func addArmorEntries(entries *[]craftEntry, armorType string) {
-	var keys []string
-	switch armorType {
-	case "Helmet":
-		keys = getSortedKeys(structures.AllHelmets)
-	case "Chestplate":
-		keys = getSortedKeys(structures.AllChestplates)
-	case "Boots":
-		keys = getSortedKeys(structures.AllBoots)
-	}
-
-	for _, name := range keys {
-		*entries = append(*entries, craftEntry{
-			label:     fmt.Sprintf("%s: %s", armorType, name),
-			kind:      "armor",
-			armorType: armorType,
-			armorName: name,
-		})
-	}
-}
-
-func buildCraftEntries() []craftEntry {
-	if cachedCraftEntries != nil {
-		return cachedCraftEntries
-	}
-
-	entries := []craftEntry{}
-
-	weaponKeys := getSortedKeys(structures.AllWeapons)
-	for _, name := range weaponKeys {
-		entries = append(entries, craftEntry{
-			label:      fmt.Sprintf("Weapon: %s", name),
-			kind:       "weapon",
-			weaponName: name,
-		})
-	}
-
-	addArmorEntries(&entries, "Helmet")
-	addArmorEntries(&entries, "Chestplate")
-	addArmorEntries(&entries, "Boots")
-
-	cachedCraftEntries = entries
-	return entries
+	return fmt.Sprintf("\033[90m[Locked] %s\033[0m", r.Name) // Grey out undiscovered recipes
 }
 
 func getArmor(armorType, armorName string) structures.Armors {
-	switch armorType {
-	case "Helmet":
-		return structures.AllHelmets[armorName]
-	case "Chestplate":
-		return structures.AllChestplates[armorName]
-	case "Boots":
-		return structures.AllBoots[armorName]
-	default:
-		return structures.Armors{}
-	}
-}
-
-func computeRequirements(entry craftEntry) (minutes int, materials map[string]int, gold int) {
-	switch entry.kind {
-	case "weapon":
-		w := structures.AllWeapons[entry.weaponName]
-		m, req := computeWeaponReq(w)
-		return m, req, 5
-	case "armor":
-		a := getArmor(entry.armorType, entry.armorName)
-		m, req, gold := computeArmorReq(a)
-		return m, req, gold
-	}
-	return 0, map[string]int{}, 0
-}
-
-func computeWeaponReq(w structures.Weapon) (int, map[string]int) {
-	rarity := rarityFromWeaponDamage(w.Damage)
-	_, mats := structures.CraftingRulesForWeapon(w)
-
-	var goldCost int
-	switch rarity {
-	case 4:
-		goldCost = 10
-	case 3:
-		goldCost = 7
-	case 2:
-		goldCost = 5
-	default:
-		goldCost = 3
-	}
-
-	return goldCost, mats
-}
-
-func computeArmorReq(a structures.Armors) (int, map[string]int, int) {
-	rarity := rarityFromArmorName(a.Name)
-	mats := map[string]int{}
-	baseGold := 5
-	typeMult := 1
-	switch a.Type {
-	case "Chestplate":
-		typeMult = 2
-		baseGold = 8
-	case "Helmet", "Boots":
-		typeMult = 1
-		baseGold = 5
-	}
-	switch rarity {
-	case 6:
-		mats["OrcTusk"] = 4 * typeMult
-		mats["SkeletonBone"] = 2 * typeMult
-		return 10, mats, baseGold
-	case 4:
-		mats["OrcTusk"] = 2 * typeMult
-		mats["SkeletonBone"] = 2 * typeMult
-		return 7, mats, baseGold
-	default:
-		mats["GoblinEar"] = 2 * typeMult
-		return 3, mats, baseGold
-	}
-}
-
-func rarityFromWeaponDamage(dmg int) int {
-	switch {
-	case dmg >= 25:
-		return 4
-	case dmg >= 18:
-		return 3
-	case dmg >= 13:
-		return 2
-	default:
-		return 1
-	}
-}
-
-func rarityFromArmorName(name string) int {
-	switch name {
-	case "StormBringer":
-		return 6
-	case "SunBreaker":
-		return 4
-	default:
-		return 2
-	}
+	return structures.GetArmorByType(armorType, armorName)
 }
 
 func updateBsHover(g *gocui.Gui) {
@@ -194,18 +48,14 @@ func updateBsHover(g *gocui.Gui) {
 	}
 }
 
-func attemptCraft(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, player *structures.Player, entry craftEntry) error {
+func attemptCraft(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, player *structures.Player, entry structures.Recipe) error {
 	if blacksmith.Current != nil {
 		return ShowMessageWithOk(g, "bs", "Blacksmith", "Blacksmith is already working on a job", 60, 7)
 	}
-	var ok bool
-	switch entry.kind {
-	case "weapon":
-		ok = blacksmith.RequestCraftWeapon(player, entry.weaponName)
-	case "armor":
-		ok = blacksmith.RequestCraftArmor(player, entry.armorType, entry.armorName)
+	if !player.KnowsRecipe(entry.Key) {
+		return ShowMessageWithOk(g, "bs", "Blacksmith", "Recipe not discovered yet: "+entry.UnlockHint(), 60, 7)
 	}
-	if ok {
+	if blacksmith.RequestCraft(player, entry.Key) {
 		_ = save.SaveAny("player", player)
 		_ = save.SaveAny("blacksmith_job", blacksmith.Current)
 		return ShowMessageWithOk(g, "bs", "Blacksmith", "Crafting started!", 60, 7)
@@ -213,8 +63,19 @@ func attemptCraft(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, playe
 	return ShowMessageWithOk(g, "bs", "Blacksmith", "Cannot start crafting (check gold/materials or weight)", 60, 7)
 }
 
+func attemptRepair(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, player *structures.Player) error {
+	cost, ok := blacksmith.RepairAll(player)
+	if cost == 0 {
+		return ShowMessageWithOk(g, "bs", "Blacksmith", "Your gear doesn't need any repair", 60, 7)
+	}
+	if !ok {
+		return ShowMessageWithOk(g, "bs", "Blacksmith", fmt.Sprintf("Repairing costs %d gold, you don't have enough", cost), 60, 7)
+	}
+	_ = save.SaveAny("player", player)
+	return ShowMessageWithOk(g, "bs", "Blacksmith", fmt.Sprintf("Gear repaired for %d gold", cost), 60, 7)
+}
+
 func resetBlacksmithCache() {
-	cachedCraftEntries = nil
 	blacksmithSelected = 0
 }
 
@@ -259,13 +120,13 @@ func blacksmithLayout(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, p
 		if !errors.Is(err, gocui.ErrUnknownView) {
 			return err
 		}
-		v.Title = " Craftable items "
+		v.Title = " Recipe book "
 		v.Highlight = false
 	}
 	if v, err := g.View("bs_list"); err == nil {
 		lines := make([]string, 0, len(entries))
 		for _, e := range entries {
-			lines = append(lines, e.label)
+			lines = append(lines, recipeLabel(e, player))
 		}
 		RenderListWithHighlight(v, lines, blacksmithSelected)
 		if len(entries) == 0 {
@@ -300,20 +161,24 @@ func blacksmithLayout(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, p
 		} else {
 			ValidateSelectedIndex(&blacksmithSelected, len(entries))
 			entry := entries[blacksmithSelected]
-			mins, mats, gold := computeRequirements(entry)
-			fmt.Fprintf(v, "Selected: %s\n", entry.label)
-			fmt.Fprintf(v, "Time: %d min\n", mins)
+			mins, mats, gold := entry.Minutes, entry.Materials, entry.Gold
+			fmt.Fprintf(v, "Selected: %s\n", entry.Name)
+			if !player.KnowsRecipe(entry.Key) {
+				fmt.Fprintf(v, "Locked: %s\n", entry.UnlockHint())
+			}
+			fmt.Fprintf(v, "Time: %d min (tier and affixes are rolled when done)\n", mins)
 			goldMark := "✗"
 			if player.Money >= gold {
 				goldMark = "✓"
 			}
 			fmt.Fprintf(v, "Gold: %d (you: %d) %s\n", gold, player.Money, goldMark)
-			if entry.kind == "weapon" {
-				w := structures.AllWeapons[entry.weaponName]
+			if entry.OutputType == "weapon" {
+				w := structures.AllWeapons[entry.WeaponName]
 				fmt.Fprintf(v, "Damage: %d\n", w.Damage)
+				fmt.Fprintf(v, "Mechanic: %s\n", w.MechanicDescription())
 				fmt.Fprintf(v, "Defense: 0\n")
 			} else {
-				a := getArmor(entry.armorType, entry.armorName)
+				a := getArmor(entry.ArmorType, entry.ArmorName)
 				fmt.Fprintf(v, "Damage: 0\n")
 				fmt.Fprintf(v, "Defense: %d\n", a.Defense)
 			}
@@ -350,20 +215,30 @@ func blacksmithLayout(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, p
 	if v, err := g.View("bs_build"); err == nil {
 		v.Clear()
 		wdmg := player.Weapon.Damage
-		fmt.Fprintf(v, "Weapon: %s (Damage %d)\n", player.Weapon.Name, wdmg)
+		fmt.Fprintf(v, "Weapon: %s (Damage %d, Dur %d/%d)\n", colorByTier(player.Weapon.DisplayName(), player.Weapon.Tier), wdmg, player.Weapon.Durability, player.Weapon.MaxDurability)
 		h := player.Entity.Helmet
 		c := player.Entity.Chestplate
 		b := player.Entity.Boots
-		fmt.Fprintf(v, "Helmet: %s (Def %d)\n", h.Name, h.Defense)
-		fmt.Fprintf(v, "Chest: %s (Def %d)\n", c.Name, c.Defense)
-		fmt.Fprintf(v, "Boots: %s (Def %d)\n", b.Name, b.Defense)
-		baseDef := h.Defense + c.Defense + b.Defense
+		fmt.Fprintf(v, "Helmet: %s (Def %d, Dur %d/%d)\n", colorByTier(h.Name, h.Tier), h.Defense, h.Durability, h.MaxDurability)
+		fmt.Fprintf(v, "Chest: %s (Def %d, Dur %d/%d)\n", colorByTier(c.Name, c.Tier), c.Defense, c.Durability, c.MaxDurability)
+		fmt.Fprintf(v, "Boots: %s (Def %d, Dur %d/%d)\n", colorByTier(b.Name, b.Tier), b.Defense, b.Durability, b.MaxDurability)
+		sh := player.Entity.Shield
+		fmt.Fprintf(v, "Shield: %s (Def %d, Block %d%%, Dur %d/%d)\n", colorByTier(sh.Name, sh.Tier), sh.Defense, sh.BlockChance, sh.Durability, sh.MaxDurability)
+		fmt.Fprintf(v, "Rings: %s, %s | Amulet: %s\n", colorByTier(player.Entity.Ring1.Name, player.Entity.Ring1.Tier),
+			colorByTier(player.Entity.Ring2.Name, player.Entity.Ring2.Tier), colorByTier(player.Entity.Amulet.Name, player.Entity.Amulet.Tier))
+		baseDef := h.Defense + c.Defense + b.Defense + sh.Defense
 		setBonus := structures.GetSetBonusDefense(player.Entity)
 		totalDef := baseDef + setBonus
 		if setBonus > 0 {
 			fmt.Fprintf(v, "Set bonus: +%d\n", setBonus)
 		}
+		for _, line := range player.Entity.SetProgress() {
+			fmt.Fprintf(v, "Set %s\n", line)
+		}
 		fmt.Fprintf(v, "Total defense: %d\n", totalDef)
+		if cost := player.RepairCost(); cost > 0 {
+			fmt.Fprintf(v, "Repair all (R): %d gold\n", cost)
+		}
 	}
 
 	closeBtnX := maxX - 12
@@ -378,11 +253,16 @@ func blacksmithLayout(g *gocui.Gui, blacksmith *structures.CraftingBlacksmith, p
 	if craftBtnX < 2 {
 		craftBtnX = 2
 	}
+	repairBtnX := craftBtnX - 12
+	if repairBtnX < 2 {
+		repairBtnX = 2
+	}
 	createButton(g, "bs_craft", " Craft ", craftBtnX, closeBtnY, 10, 2, "bs_craft")
+	createButton(g, "bs_repair", " Repair ", repairBtnX, closeBtnY, 10, 2, "bs_repair")
 	createButton(g, "bs_close", " Close ", closeBtnX, closeBtnY, 10, 2, "bs_close")
 
 	if blacksmith.Current != nil && time.Now().After(blacksmith.Current.ReadyAt) {
-		collectX := craftBtnX - 14
+		collectX := repairBtnX - 14
 		if collectX < 2 {
 			collectX = 2
 		}
@@ -423,6 +303,10 @@ func blacksmithKeybindings(g *gocui.Gui, blacksmith *structures.CraftingBlacksmi
 		return attemptCraft(g, blacksmith, player, entry)
 	})
 
+	g.SetKeybinding("", 'r', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return attemptRepair(g, blacksmith, player)
+	})
+
 	g.SetKeybinding("", 'c', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
 		if blacksmith.Current == nil {
 			return nil
@@ -465,6 +349,9 @@ func blacksmithKeybindings(g *gocui.Gui, blacksmith *structures.CraftingBlacksmi
 			{"bs_close", func(g *gocui.Gui, v *gocui.View) error {
 				return gocui.ErrQuit
 			}},
+			{"bs_repair", func(g *gocui.Gui, v *gocui.View) error {
+				return attemptRepair(g, blacksmith, player)
+			}},
 			{"bs_craft", func(g *gocui.Gui, v *gocui.View) error {
 				entries := buildCraftEntries()
 				if len(entries) == 0 || !IsValidIndex(blacksmithSelected, len(entries)) {
@@ -531,17 +418,16 @@ func performReplaceCollect(g *gocui.Gui, blacksmith *structures.CraftingBlacksmi
 	}
 	switch blacksmith.Current.Request.OutputType {
 	case "weapon":
-		w := structures.AllWeapons[blacksmith.Current.Request.WeaponName]
-		player.Weapon = w
+		w := structures.RollWeapon(structures.AllWeapons[blacksmith.Current.Request.WeaponName])
+		if !player.EquipWeapon(w) {
+			DeleteViews(g, "bs_confirm", "bs_confirm_yes", "bs_confirm_no")
+			return ShowMessageWithOk(g, "bs", "Blacksmith", "Your cursed weapon won't come off, lift the curse first", 60, 7)
+		}
 	case "armor":
-		a := getArmor(blacksmith.Current.Request.ArmorType, blacksmith.Current.Request.ArmorName)
-		switch blacksmith.Current.Request.ArmorType {
-		case "Helmet":
-			player.Entity.Helmet = a
-		case "Chestplate":
-			player.Entity.Chestplate = a
-		case "Boots":
-			player.Entity.Boots = a
+		a := structures.RollArmor(getArmor(blacksmith.Current.Request.ArmorType, blacksmith.Current.Request.ArmorName))
+		if !player.Entity.EquipArmor(a) {
+			DeleteViews(g, "bs_confirm", "bs_confirm_yes", "bs_confirm_no")
+			return ShowMessageWithOk(g, "bs", "Blacksmith", "Your cursed armor won't come off, lift the curse first", 60, 7)
 		}
 	}
 	blacksmith.Current = nil
@@ -4,16 +4,28 @@ import (
 	"fmt"
 	"strings"
 
+	"main/pkg/save"
 	"main/pkg/structures"
 
 	"github.com/awesome-gocui/gocui"
 )
 
+// Set by the game, scrolls act on the dungeon which the inventory knows nothing about.
+// Returns the message to show and whether the scroll was used up
+var ReadScroll func(g *gocui.Gui, player *structures.Player, scroll structures.Scroll) (string, bool)
+
 var (
 	inventoryOpen     = false
-	inventorySelected = 0
+	inventorySelected = 0 // Index in the filtered list, not in player.Inventory
+	inventoryCategory = 0
+	inventorySearch   = ""
 )
 
+// Indices in player.Inventory of the items shown with the current tab, search and sort
+func inventoryRows(player *structures.Player) []int {
+	return player.ListInventory(structures.InventoryCategories[inventoryCategory], inventorySearch)
+}
+
 func ShowInventory(g *gocui.Gui, player *structures.Player) error {
 	if inventoryOpen {
 		return CloseInventory(g)
@@ -21,9 +33,11 @@ func ShowInventory(g *gocui.Gui, player *structures.Player) error {
 
 	inventoryOpen = true
 	inventorySelected = 0
+	inventoryCategory = 0
+	inventorySearch = ""
 
 	maxX, maxY := g.Size()
-	width := 60
+	width := 72
 	height := 25
 	x := (maxX - width) / 2
 	y := (maxY - height) / 2
@@ -47,13 +61,9 @@ func ShowInventory(g *gocui.Gui, player *structures.Player) error {
 
 func CloseInventory(g *gocui.Gui) error {
 	inventoryOpen = false
+	closeInventorySearch(g)
 	g.DeleteView("inventory")
-	g.DeleteKeybinding("inventory", gocui.KeyArrowUp, gocui.ModNone)
-	g.DeleteKeybinding("inventory", gocui.KeyArrowDown, gocui.ModNone)
-	g.DeleteKeybinding("inventory", 'e', gocui.ModNone)
-	g.DeleteKeybinding("inventory", 'E', gocui.ModNone)
-	g.DeleteKeybinding("inventory", gocui.KeyEsc, gocui.ModNone)
-	g.DeleteKeybinding("inventory", gocui.KeyEnter, gocui.ModNone)
+	disableInventoryKeybindings(g)
 	return nil
 }
 
@@ -62,32 +72,71 @@ func updateInventoryView(v *gocui.View, player *structures.Player) {
 
 	fmt.Fprintf(v, " Player: %s\n", player.Entity.Name)
 	fmt.Fprintf(v, " Money: %d coins\n", player.Money)
-	fmt.Fprintf(v, " Carry Weight: %d/%d\n", player.CurrentCarryWeight(), player.MaxCarryWeight)
-	fmt.Fprintln(v, strings.Repeat("-", 56))
+	fmt.Fprintf(v, " Carry Weight: %d/%d (%s, max %d)\n", player.CurrentCarryWeight(), player.MaxCarryWeight, player.Encumbrance().Name, player.HardCarryWeight())
+	fmt.Fprintf(v, " Weapon: %s (Dur: %d/%d)\n", player.Weapon.DisplayName(), player.Weapon.Durability, player.Weapon.MaxDurability)
+	fmt.Fprintf(v, " Shield: %s | Rings: %s, %s | Amulet: %s\n", player.Entity.Shield.Name, player.Entity.Ring1.Name, player.Entity.Ring2.Name, player.Entity.Amulet.Name)
+	for _, line := range player.Entity.SetProgress() {
+		fmt.Fprintf(v, " Set %s\n", line)
+	}
+	fmt.Fprintln(v, strings.Repeat("-", 68))
+
+	tabs := ""
+	for i, category := range structures.InventoryCategories {
+		if i == inventoryCategory {
+			tabs += fmt.Sprintf("\033[43m\033[30m %s \033[0m", category)
+		} else {
+			tabs += fmt.Sprintf(" %s ", category)
+		}
+	}
+	fmt.Fprintf(v, " %s\n", tabs)
+	sortName := player.InventorySort
+	if sortName == "" {
+		sortName = structures.InventorySorts[0]
+	}
+	search := inventorySearch
+	if search == "" {
+		search = "(none)"
+	}
+	fmt.Fprintf(v, " Sort: %s  |  Search: %s\n", sortName, search)
 
 	if len(player.Inventory) == 0 {
 		fmt.Fprintln(v, " Your inventory is empty.")
 		return
 	}
 
-	fmt.Fprintln(v, " Items:")
-	for i, entry := range player.Inventory {
+	rows := inventoryRows(player)
+	if len(rows) == 0 {
+		fmt.Fprintln(v, " No items match.")
+	} else {
+		fmt.Fprintf(v, " Items (%d/%d):\n", len(rows), len(player.Inventory))
+	}
+	for i, index := range rows {
+		entry := player.Inventory[index]
 		item := entry.GetItem()
+		if i != inventorySelected {
+			item.Name = colorByTier(item.Name, structures.ItemTier(entry))
+		}
 
 		var line string
 		switch e := entry.(type) {
 		case structures.Material:
 			line = fmt.Sprintf("[Material] %s", item.Name)
+		case structures.WeaponItem, structures.ArmorItem:
+			if structures.IsUnidentified(entry) {
+				line = fmt.Sprintf("[???] %s (Stats unknown, equip or identify it)", item.Name)
+				break
+			}
+			line = gearLine(entry, item.Name)
 		case structures.Potion:
 			line = fmt.Sprintf("[Potion] %s (Size: %d)", item.Name, e.Size)
 		case structures.Spellbooks:
 			line = fmt.Sprintf("[Spellbook] %s (Spell: %s)", item.Name, e.Spell.Name)
-		case structures.WeaponItem:
-			line = fmt.Sprintf("[Weapon] %s (Damage: %d)", item.Name, e.Weapon.Damage)
-		case structures.ArmorItem:
-			line = fmt.Sprintf("[Armor] %s (Defense: %d)", item.Name, e.Armor.Defense)
 		case structures.BackpackItem:
 			line = fmt.Sprintf("[Backpack] %s (+%d Weight Capacity)", item.Name, e.CapacityIncrease)
+		case structures.RecipeScroll:
+			line = fmt.Sprintf("[Recipe] %s", item.Name)
+		case structures.Scroll:
+			line = fmt.Sprintf("[Scroll] %s", item.Name)
 		default:
 			line = fmt.Sprintf("%s (Weight: %d)", item.Name, item.Weight)
 		}
@@ -100,14 +149,41 @@ func updateInventoryView(v *gocui.View, player *structures.Player) {
 		}
 	}
 
-	fmt.Fprintln(v, strings.Repeat("-", 56))
+	fmt.Fprintln(v, strings.Repeat("-", 68))
 	fmt.Fprintln(v, " Controls:")
 	fmt.Fprintln(v, " ↑/↓ - Navigate  |  Enter - Use Item  |  E/Esc - Close")
+	fmt.Fprintln(v, " ←/→ - Category  |  O - Sort  |  / - Search")
+}
+
+// Stats of identified weapons and armors
+func gearLine(entry structures.InventoryEntry, name string) string {
+	switch e := entry.(type) {
+	case structures.WeaponItem:
+		return fmt.Sprintf("[Weapon] %s (Damage: %d, Dur: %d/%d%s)", name, e.Weapon.Damage, e.Weapon.Durability, e.Weapon.MaxDurability, describeAffixes(e.Weapon.Affixes))
+	case structures.ArmorItem:
+		switch e.Armor.Type {
+		case "Ring", "Amulet":
+			return fmt.Sprintf("[%s] %s (%s)", e.Armor.Type, name, strings.TrimPrefix(describeAffixes(append(e.Armor.BaseStats, e.Armor.Affixes...)), ", "))
+		case "Shield":
+			return fmt.Sprintf("[Shield] %s (Defense: %d, Block: %d%%, Dur: %d/%d%s)", name, e.Armor.Defense, e.Armor.BlockChance, e.Armor.Durability, e.Armor.MaxDurability, describeAffixes(e.Armor.Affixes))
+		default:
+			return fmt.Sprintf("[Armor] %s (Defense: %d, Dur: %d/%d%s)", name, e.Armor.Defense, e.Armor.Durability, e.Armor.MaxDurability, describeAffixes(e.Armor.Affixes))
+		}
+	}
+	return name
+}
+
+func describeAffixes(affixes []structures.Affix) string {
+	text := ""
+	for _, a := range affixes {
+		text += fmt.Sprintf(", %+d %s", a.Value, a.Stat)
+	}
+	return text
 }
 
 func setupInventoryKeybindings(g *gocui.Gui, player *structures.Player) error {
 	if err := g.SetKeybinding("inventory", gocui.KeyArrowUp, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
-		if len(player.Inventory) > 0 && inventorySelected > 0 {
+		if inventorySelected > 0 {
 			inventorySelected--
 			updateInventoryView(v, player)
 		}
@@ -117,7 +193,7 @@ func setupInventoryKeybindings(g *gocui.Gui, player *structures.Player) error {
 	}
 
 	if err := g.SetKeybinding("inventory", gocui.KeyArrowDown, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
-		if len(player.Inventory) > 0 && inventorySelected < len(player.Inventory)-1 {
+		if inventorySelected < len(inventoryRows(player))-1 {
 			inventorySelected++
 			updateInventoryView(v, player)
 		}
@@ -151,15 +227,100 @@ func setupInventoryKeybindings(g *gocui.Gui, player *structures.Player) error {
 		return err
 	}
 
+	if err := g.SetKeybinding("inventory", gocui.KeyArrowLeft, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		inventoryCategory = (inventoryCategory + len(structures.InventoryCategories) - 1) % len(structures.InventoryCategories)
+		inventorySelected = 0
+		updateInventoryView(v, player)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding("inventory", gocui.KeyArrowRight, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		inventoryCategory = (inventoryCategory + 1) % len(structures.InventoryCategories)
+		inventorySelected = 0
+		updateInventoryView(v, player)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, key := range []rune{'o', 'O'} {
+		if err := g.SetKeybinding("inventory", key, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			player.InventorySort = structures.NextInventorySort(player.InventorySort)
+			_ = save.SaveAny("player", player)
+			updateInventoryView(v, player)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding("inventory", '/', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return openInventorySearch(g, player)
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// Editable box under the inventory, the list is filtered while typing
+func openInventorySearch(g *gocui.Gui, player *structures.Player) error {
+	inv, err := g.View("inventory")
+	if err != nil {
+		return nil
+	}
+	x0, _, x1, y1 := inv.Dimensions()
+	v, err := g.SetView("inventory_search", x0, y1+1, x1, y1+3, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = " Search (Enter to keep, Esc to clear) "
+		v.Frame = true
+		v.Editable = true
+		v.Wrap = false
+		fmt.Fprint(v, inventorySearch)
+		v.SetCursor(len(inventorySearch), 0)
+		v.Editor = gocui.EditorFunc(func(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+			gocui.DefaultEditor.Edit(v, key, ch, mod)
+			inventorySearch = strings.TrimSpace(v.Buffer())
+			inventorySelected = 0
+			updateInventoryView(inv, player)
+		})
+	}
+
+	g.SetKeybinding("inventory_search", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		closeInventorySearch(g)
+		_, err := g.SetCurrentView("inventory")
+		return err
+	})
+	g.SetKeybinding("inventory_search", gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		inventorySearch = ""
+		inventorySelected = 0
+		updateInventoryView(inv, player)
+		closeInventorySearch(g)
+		_, err := g.SetCurrentView("inventory")
+		return err
+	})
+
+	_, err = g.SetCurrentView("inventory_search")
+	return err
+}
+
+func closeInventorySearch(g *gocui.Gui) {
+	g.DeleteKeybindings("inventory_search")
+	g.DeleteView("inventory_search")
+}
+
 func useSelectedItem(g *gocui.Gui, v *gocui.View, player *structures.Player) error {
-	if len(player.Inventory) == 0 || inventorySelected >= len(player.Inventory) {
+	rows := inventoryRows(player)
+	if !IsValidIndex(inventorySelected, len(rows)) {
 		return nil
 	}
 
-	selectedItem := player.Inventory[inventorySelected]
+	selectedItem := player.Inventory[rows[inventorySelected]]
 
 	switch item := selectedItem.(type) {
 	case structures.Potion:
@@ -184,6 +345,20 @@ func useSelectedItem(g *gocui.Gui, v *gocui.View, player *structures.Player) err
 					fmt.Sprintf("Used %s! Restored full health (move to update) ?", item.Item.Name), 40, 8)
 			}
 		}
+		if item.Type == "Mana" {
+			if player.UsePotion(item) {
+				updateInventoryView(v, player)
+				ShowMessageWithOk(g, "potion", "Item Used",
+					fmt.Sprintf("Used %s! Restored mana (%d/%d).", item.Item.Name, player.Mana, player.MaxMana()), 40, 8)
+			}
+		}
+		if item.Type == "Strength" || item.Type == "Resistance" || item.Type == "Haste" {
+			if player.UsePotion(item) {
+				updateInventoryView(v, player)
+				ShowMessageWithOk(g, "potion", "Item Used",
+					fmt.Sprintf("Used %s! %s lasts for your next fight turns.", item.Item.Name, item.Type), 50, 8)
+			}
+		}
 	case structures.Spellbooks:
 		hasSpell := false
 		for _, spell := range player.Spells {
@@ -204,26 +379,24 @@ func useSelectedItem(g *gocui.Gui, v *gocui.View, player *structures.Player) err
 				"You already know this spell!", 40, 8)
 		}
 	case structures.WeaponItem:
-		player.Weapon = item.Weapon
+		if !player.EquipWeapon(item.Weapon) {
+			return ShowMessageWithOk(g, "weapon", "Cursed",
+				fmt.Sprintf("Your %s is cursed and won't leave your hand!", player.Weapon.DisplayName()), 56, 8)
+		}
 		player.RemoveItem(selectedItem)
 		ensureValidSelection(player)
 		updateInventoryView(v, player)
-		ShowMessageWithOk(g, "weapon", "Weapon Equipped",
-			fmt.Sprintf("Equipped %s!", item.Weapon.Name), 40, 8)
+		ShowMessageWithOk(g, "weapon", "Weapon Equipped", equipMessage(player.Weapon.DisplayName(), player.Weapon.Cursed), 56, 8)
 	case structures.ArmorItem:
-		switch item.Armor.Type {
-		case "Helmet":
-			player.Entity.Helmet = item.Armor
-		case "Chestplate":
-			player.Entity.Chestplate = item.Armor
-		case "Boots":
-			player.Entity.Boots = item.Armor
+		if !player.Entity.EquipArmor(item.Armor) {
+			return ShowMessageWithOk(g, "armor", "Cursed",
+				fmt.Sprintf("A cursed %s is stuck on you, lift the curse first!", strings.ToLower(item.Armor.Type)), 56, 8)
 		}
+		item.Armor.Unidentified = false
 		player.RemoveItem(selectedItem)
 		ensureValidSelection(player)
 		updateInventoryView(v, player)
-		ShowMessageWithOk(g, "armor", "Armor Equipped",
-			fmt.Sprintf("Equipped %s!", item.Item.Name), 40, 8)
+		ShowMessageWithOk(g, "armor", "Armor Equipped", equipMessage(item.Armor.DisplayName(), item.Armor.Cursed), 56, 8)
 	case structures.BackpackItem:
 		if player.UseBackpack(item) {
 			ensureValidSelection(player)
@@ -232,6 +405,30 @@ func useSelectedItem(g *gocui.Gui, v *gocui.View, player *structures.Player) err
 				fmt.Sprintf("Used %s! Increased carry capacity by %d (Now %d/%d)!",
 					item.Item.Name, item.CapacityIncrease, player.CurrentCarryWeight(), player.MaxCarryWeight), 50, 8)
 		}
+	case structures.RecipeScroll:
+		if player.UseRecipeScroll(item) {
+			ensureValidSelection(player)
+			updateInventoryView(v, player)
+			ShowMessageWithOk(g, "recipe", "Recipe Learned",
+				fmt.Sprintf("Added %s to your recipe book!", item.Item.Name), 50, 8)
+		} else {
+			ShowMessageWithOk(g, "recipe", "Already Known",
+				"This recipe is already in your recipe book!", 50, 8)
+		}
+	case structures.Scroll:
+		if ReadScroll == nil {
+			return showInventoryPopup(g, "Scroll", "Nothing happens.", player)
+		}
+		msg, used := ReadScroll(g, player, item)
+		if used {
+			player.RemoveItem(selectedItem)
+			_ = save.SaveAny("player", player)
+		}
+		if inventoryOpen { // Some scrolls close the inventory to move the player
+			ensureValidSelection(player)
+			updateInventoryView(v, player)
+		}
+		ShowMessageWithOk(g, "scroll", "Scroll", msg, 56, 8)
 	default:
 		showInventoryPopup(g, "Item Info",
 			fmt.Sprintf("%s - This item cannot be used directly.", selectedItem.GetItem().Name), player)
@@ -240,11 +437,19 @@ func useSelectedItem(g *gocui.Gui, v *gocui.View, player *structures.Player) err
 	return nil
 }
 
+func equipMessage(name string, cursed bool) string {
+	if cursed {
+		return fmt.Sprintf("Equipped %s! A dark curse binds it to you.", name)
+	}
+	return fmt.Sprintf("Equipped %s!", name)
+}
+
 func ensureValidSelection(player *structures.Player) {
-	if len(player.Inventory) == 0 {
+	rows := inventoryRows(player)
+	if len(rows) == 0 {
 		inventorySelected = 0
-	} else if inventorySelected >= len(player.Inventory) {
-		inventorySelected = len(player.Inventory) - 1
+	} else if inventorySelected >= len(rows) {
+		inventorySelected = len(rows) - 1
 	}
 }
 
@@ -255,6 +460,11 @@ func disableInventoryKeybindings(g *gocui.Gui) {
 	g.DeleteKeybinding("inventory", 'E', gocui.ModNone)
 	g.DeleteKeybinding("inventory", gocui.KeyEsc, gocui.ModNone)
 	g.DeleteKeybinding("inventory", gocui.KeyEnter, gocui.ModNone)
+	g.DeleteKeybinding("inventory", gocui.KeyArrowLeft, gocui.ModNone)
+	g.DeleteKeybinding("inventory", gocui.KeyArrowRight, gocui.ModNone)
+	g.DeleteKeybinding("inventory", 'o', gocui.ModNone)
+	g.DeleteKeybinding("inventory", 'O', gocui.ModNone)
+	g.DeleteKeybinding("inventory", '/', gocui.ModNone)
 }
 
 func showInventoryPopup(g *gocui.Gui, title, message string, player *structures.Player) error {
@@ -55,6 +55,22 @@ func attemptPurchase(g *gocui.Gui, merchant *structures.Merchant, player *struct
 	return ShowMessageWithOk(g, "merchant", "Merchant", "Purchase failed", 50, 7)
 }
 
+func identifyService(g *gocui.Gui, merchant *structures.Merchant, player *structures.Player) error {
+	count := merchant.Identify(player)
+	if count == 0 {
+		return ShowMessageWithOk(g, "merchant", "Merchant", fmt.Sprintf("Nothing to identify, or not enough gold (%d each)", structures.IdentifyPrice), 56, 7)
+	}
+	return ShowMessageWithOk(g, "merchant", "Merchant", fmt.Sprintf("Identified %d item(s) for %d gold", count, count*structures.IdentifyPrice), 56, 7)
+}
+
+func uncurseService(g *gocui.Gui, merchant *structures.Merchant, player *structures.Player) error {
+	count := merchant.RemoveCurses(player)
+	if count == 0 {
+		return ShowMessageWithOk(g, "merchant", "Merchant", fmt.Sprintf("No known curse, or not enough gold (%d each)", structures.UncursePrice), 56, 7)
+	}
+	return ShowMessageWithOk(g, "merchant", "Merchant", fmt.Sprintf("Lifted %d curse(s) for %d gold", count, count*structures.UncursePrice), 56, 7)
+}
+
 func ShowMerchantMenu(merchant *structures.Merchant, player *structures.Player) {
 	merchantSelected = 0
 	g, _ := gocui.NewGui(gocui.OutputNormal, false)
@@ -91,7 +107,7 @@ func merchantLayout(g *gocui.Gui, merchant *structures.Merchant, player *structu
 	if err := SetOrUpdateView(g, "merchant_title", 0, 0, maxX-1, 2, func(v *gocui.View) {
 		v.Frame = false
 	}, func(v *gocui.View) {
-		fmt.Fprintf(v, "  Merchant • Gold: %d\n", player.Money)
+		fmt.Fprintf(v, "  Merchant • Gold: %d • I=Identify (%d each)  U=Lift curses (%d each)\n", player.Money, structures.IdentifyPrice, structures.UncursePrice)
 	}); err != nil {
 		return err
 	}
@@ -119,7 +135,7 @@ func merchantLayout(g *gocui.Gui, merchant *structures.Merchant, player *structu
 			lines := make([]string, 0, len(merchant.Inventory))
 			for _, entry := range merchant.Inventory {
 				item := entry.GetItem()
-				lines = append(lines, fmt.Sprintf("%s  | Price: %d  | Rarity: %d", item.Name, item.Price, item.Rarity))
+				lines = append(lines, fmt.Sprintf("%s  | Price: %d  | Rarity: %d", colorByTier(item.Name, structures.ItemTier(entry)), item.Price, item.Rarity))
 			}
 			RenderListWithHighlight(v, lines, merchantSelected)
 		}
@@ -136,7 +152,7 @@ func merchantLayout(g *gocui.Gui, merchant *structures.Merchant, player *structu
 		v.Clear()
 		for _, entry := range player.Inventory {
 			item := entry.GetItem()
-			fmt.Fprintf(v, "%s  | Rarity: %d\n", item.Name, item.Rarity)
+			fmt.Fprintf(v, "%s  | Rarity: %d\n", colorByTier(item.Name, structures.ItemTier(entry)), item.Rarity)
 		}
 		if len(player.Inventory) == 0 {
 			fmt.Fprintln(v, "(Empty)")
@@ -167,6 +183,16 @@ func merchantKeybindings(g *gocui.Gui, merchant *structures.Merchant, player *st
 	g.SetKeybinding("merchant_list", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
 		return attemptPurchase(g, merchant, player, merchantSelected)
 	})
+	for _, key := range []rune{'i', 'I'} {
+		g.SetKeybinding("", key, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			return identifyService(g, merchant, player)
+		})
+	}
+	for _, key := range []rune{'u', 'U'} {
+		g.SetKeybinding("", key, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			return uncurseService(g, merchant, player)
+		})
+	}
 
 	EnableMouseAndSetHandler(g, func(g *gocui.Gui, v *gocui.View) error {
 		mx, my := g.MousePosition()
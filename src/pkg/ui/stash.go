@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"main/pkg/structures"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+var (
+	stashInvSelected   int
+	stashItemsSelected int
+	stashShowShared    bool
+)
+
+func stashLines(entries structures.Inventory) []string {
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		item := entry.GetItem()
+		lines = append(lines, fmt.Sprintf("%s (W: %d)", colorByTier(item.Name, structures.ItemTier(entry)), item.Weight))
+	}
+	return lines
+}
+
+func depositSelected(g *gocui.Gui, player *structures.Player, stash *structures.Stash) error {
+	if !IsValidIndex(stashInvSelected, len(player.Inventory)) {
+		return nil
+	}
+	if !stash.Deposit(player, stashInvSelected) {
+		return ShowMessageWithOk(g, "stash", "Stash", fmt.Sprintf("The stash is full (%d items)", structures.MaxStashItems), 50, 7)
+	}
+	ValidateSelectedIndex(&stashInvSelected, len(player.Inventory))
+	return nil
+}
+
+func withdrawSelected(g *gocui.Gui, player *structures.Player, stash *structures.Stash) error {
+	if !IsValidIndex(stashItemsSelected, len(stash.Items)) {
+		return nil
+	}
+	if !stash.Withdraw(player, stashItemsSelected) {
+		return ShowMessageWithOk(g, "stash", "Stash", "Too heavy to carry", 50, 7)
+	}
+	ValidateSelectedIndex(&stashItemsSelected, len(stash.Items))
+	return nil
+}
+
+// Personal stash of the character, or the one shared by all the characters
+func currentStash(stash, shared *structures.Stash) *structures.Stash {
+	if stashShowShared {
+		return shared
+	}
+	return stash
+}
+
+func ShowStashMenu(player *structures.Player, stash, shared *structures.Stash) {
+	stashInvSelected = 0
+	stashItemsSelected = 0
+	stashShowShared = false
+	g, _ := gocui.NewGui(gocui.OutputNormal, false)
+	defer g.Close()
+
+	g.SetManagerFunc(func(g *gocui.Gui) error { return stashLayout(g, player, stash, shared) })
+	stashKeybindings(g, player, stash, shared)
+	g.MainLoop()
+}
+
+func stashLayout(g *gocui.Gui, player *structures.Player, stash, shared *structures.Stash) error {
+	maxX, maxY := g.Size()
+
+	if err := SetOrUpdateView(g, "stash_title", 0, 0, maxX-1, 2, func(v *gocui.View) {
+		v.Frame = false
+	}, func(v *gocui.View) {
+		fmt.Fprintln(v, "  Stash • Tab=Switch side  Enter=Move item  V=Personal/Shared stash  ESC=Leave")
+	}); err != nil {
+		return err
+	}
+
+	listWidth := maxX - 2
+	listHeight := maxY - 6
+	if listHeight < 5 {
+		listHeight = 5
+	}
+	leftWidth := listWidth / 2
+	rightStartX := 1 + leftWidth + 1
+	if v, err := g.SetView("stash_inventory", 1, 3, 1+leftWidth, 3+listHeight, 0); err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.Highlight = false
+	}
+	if v, err := g.View("stash_inventory"); err == nil {
+		v.Title = fmt.Sprintf(" Inventory (%d/%d) ", player.CurrentCarryWeight(), player.MaxCarryWeight)
+		v.Clear()
+		if len(player.Inventory) == 0 {
+			fmt.Fprintln(v, "(Empty)")
+		} else {
+			RenderListWithHighlight(v, stashLines(player.Inventory), stashInvSelected)
+		}
+	}
+
+	if v, err := g.SetView("stash_items", rightStartX, 3, 1+listWidth, 3+listHeight, 0); err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.Highlight = false
+	}
+	if v, err := g.View("stash_items"); err == nil {
+		current := currentStash(stash, shared)
+		name := "Stash"
+		if stashShowShared {
+			name = "Shared stash"
+		}
+		v.Title = fmt.Sprintf(" %s (%d/%d) ", name, len(current.Items), structures.MaxStashItems)
+		v.Clear()
+		if len(current.Items) == 0 {
+			fmt.Fprintln(v, "(Empty)")
+		} else {
+			RenderListWithHighlight(v, stashLines(current.Items), stashItemsSelected)
+		}
+	}
+
+	btnY := maxY - 3
+	if btnY < 3 {
+		btnY = 3
+	}
+	closeBtnX := maxX - 12
+	if closeBtnX < 2 {
+		closeBtnX = 2
+	}
+	createButton(g, "stash_switch", " Shared ", closeBtnX-12, btnY, 10, 2, "stash_switch")
+	createButton(g, "stash_close", " Close ", closeBtnX, btnY, 10, 2, "stash_close")
+
+	if g.CurrentView() == nil {
+		g.SetCurrentView("stash_inventory")
+	}
+	return nil
+}
+
+func toggleSharedStash() {
+	stashShowShared = !stashShowShared
+	stashItemsSelected = 0
+}
+
+func stashKeybindings(g *gocui.Gui, player *structures.Player, stash, shared *structures.Stash) error {
+	BindQuitOnEsc(g)
+
+	BindListNavigation(g, "stash_inventory", &stashInvSelected, func() int { return len(player.Inventory) })
+	BindListNavigation(g, "stash_items", &stashItemsSelected, func() int { return len(currentStash(stash, shared).Items) })
+
+	g.SetKeybinding("stash_inventory", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return depositSelected(g, player, currentStash(stash, shared))
+	})
+	g.SetKeybinding("stash_items", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return withdrawSelected(g, player, currentStash(stash, shared))
+	})
+	g.SetKeybinding("stash_inventory", gocui.KeyTab, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		_, err := g.SetCurrentView("stash_items")
+		return err
+	})
+	g.SetKeybinding("stash_items", gocui.KeyTab, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		_, err := g.SetCurrentView("stash_inventory")
+		return err
+	})
+	for _, view := range []string{"stash_inventory", "stash_items"} {
+		g.SetKeybinding(view, 'v', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			toggleSharedStash()
+			return nil
+		})
+	}
+
+	EnableMouseAndSetHandler(g, func(g *gocui.Gui, v *gocui.View) error {
+		mx, my := g.MousePosition()
+
+		if listView, _ := g.View("stash_inventory"); listView != nil {
+			x0, y0, x1, y1 := listView.Dimensions()
+			if mx >= x0 && mx <= x1 && my >= y0 && my <= y1 {
+				g.SetCurrentView("stash_inventory")
+				idx := my - y0 - 1
+				if IsValidIndex(idx, len(player.Inventory)) {
+					stashInvSelected = idx
+					return depositSelected(g, player, currentStash(stash, shared))
+				}
+				return nil
+			}
+		}
+		if listView, _ := g.View("stash_items"); listView != nil {
+			x0, y0, x1, y1 := listView.Dimensions()
+			if mx >= x0 && mx <= x1 && my >= y0 && my <= y1 {
+				g.SetCurrentView("stash_items")
+				idx := my - y0 - 1
+				if IsValidIndex(idx, len(currentStash(stash, shared).Items)) {
+					stashItemsSelected = idx
+					return withdrawSelected(g, player, currentStash(stash, shared))
+				}
+				return nil
+			}
+		}
+
+		buttons := []ButtonHandler{
+			{"stash_switch", func(g *gocui.Gui, v *gocui.View) error {
+				toggleSharedStash()
+				return nil
+			}},
+			{"stash_close", func(g *gocui.Gui, v *gocui.View) error { return gocui.ErrQuit }},
+			{"stash_ok", func(g *gocui.Gui, v *gocui.View) error {
+				DeleteViews(g, "stash_msg", "stash_ok")
+				return nil
+			}},
+		}
+		return HandleMouseClickButtons(g, mx, my, buttons)
+	})
+	return nil
+}
@@ -61,6 +61,22 @@ func getLengthFromString(s string) int {
 	return counter
 }
 
+var tierColors = map[string]string{
+	"Uncommon":  "\033[32m",
+	"Rare":      "\033[34m",
+	"Epic":      "\033[35m",
+	"Legendary": "\033[33m",
+}
+
+// Colors a name by its item tier, only resets the foreground so list highlights are kept
+func colorByTier(text, tier string) string {
+	color, ok := tierColors[tier]
+	if !ok {
+		return text
+	}
+	return color + text + "\033[39m"
+}
+
 func RenderListWithHighlight(v *gocui.View, lines []string, selected int) {
 	v.Clear()
 	for i, line := range lines {